@@ -0,0 +1,208 @@
+// Package decode decodes JSON request bodies and reports malformed input as RFC9457 problems.
+//
+// Decoding request bodies safely involves limiting their size, checking the Content-Type,
+// rejecting unknown members and trailing data, and describing syntax and type errors precisely.
+// JSON does all of this for net/http and chi handlers; fiber handlers use fiberwrap.DecodeJSON,
+// which shares the same rules and errors.
+//
+// Params decodes path and query parameters into tagged struct fields, and Request combines body and
+// parameter decoding with validation, as used by the typed handlers of the wrappers.
+package decode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+// DefaultMaxBodySize is the default maximum size of a decoded request body, 1 MiB.
+const DefaultMaxBodySize int64 = 1 << 20
+
+// options holds the settings of a decode operation.
+type options struct {
+	maxBodySize        int64
+	allowUnknownFields bool
+}
+
+// Option configures a decode operation.
+type Option func(*options)
+
+// WithMaxBodySize sets the maximum size of the request body in bytes.
+// Larger bodies are rejected with 413 Payload Too Large. The default is DefaultMaxBodySize.
+func WithMaxBodySize(n int64) Option {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// AllowUnknownFields accepts members of the request body that do not correspond to a field of the target,
+// which are rejected by default.
+func AllowUnknownFields() Option {
+	return func(o *options) {
+		o.allowUnknownFields = true
+	}
+}
+
+// newOptions returns the settings resulting from applying opts to the defaults.
+func newOptions(opts []Option) options {
+	o := options{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// JSON decodes the JSON body of request into v, which must be a non-nil pointer.
+// The body is limited with http.MaxBytesReader, so writer must be the ResponseWriter of the request.
+//
+// Malformed requests are reported as *httperror.RFC9457Error values which handlers can return directly:
+//   - 415 Unsupported Media Type if the Content-Type is present and not JSON;
+//   - 413 Payload Too Large if the body exceeds the maximum size;
+//   - 400 Bad Request if the body is empty, is not valid JSON (with the byte "offset" of the error),
+//     contains a value of the wrong type (with its JSON Pointer in the "errors" extension),
+//     contains an unknown member or contains more than one JSON value.
+//
+// Other errors, such as a failure to read the body or a v that is not a pointer, are returned as is.
+func JSON(writer http.ResponseWriter, request *http.Request, v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	if err := CheckContentType(request.Header.Get("Content-Type")); err != nil {
+		return err
+	}
+	if request.ContentLength > o.maxBodySize {
+		return tooLarge(o.maxBodySize)
+	}
+	return decode(http.MaxBytesReader(writer, request.Body, o.maxBodySize), v, o)
+}
+
+// Bytes decodes a request body that has already been read into memory, such as the body of a fiber request,
+// applying the same rules and returning the same errors as JSON. The Content-Type of the request is checked
+// with CheckContentType separately.
+func Bytes(body []byte, v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	if int64(len(body)) > o.maxBodySize {
+		return tooLarge(o.maxBodySize)
+	}
+	return decode(bytes.NewReader(body), v, o)
+}
+
+// CheckContentType returns a 415 Unsupported Media Type problem if contentType is present and is not
+// application/json or a media type with the +json suffix. Requests without a Content-Type are accepted.
+func CheckContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	return httperror.UnsupportedMediaTypeProblem9457("The request body must be JSON.").
+		WithExtension("accept", "application/json")
+}
+
+// decode decodes exactly one JSON value from r into v.
+func decode(r io.Reader, v interface{}, o options) error {
+	dec := json.NewDecoder(r)
+	if !o.allowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err, o)
+	}
+
+	// A second value, or anything else after the first one, is trailing data
+	offset := dec.InputOffset()
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return tooLarge(o.maxBodySize)
+		}
+		return httperror.BadRequestProblem9457("The request body must contain a single JSON value.").
+			WithExtension("offset", offset)
+	}
+	return nil
+}
+
+// decodeError converts an error returned by json.Decoder.Decode into a problem describing it.
+func decodeError(err error, o options) error {
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		maxBytesErr  *http.MaxBytesError
+		unmarshalErr *json.InvalidUnmarshalError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return tooLarge(o.maxBodySize)
+	case errors.As(err, &syntaxErr):
+		return httperror.BadRequestProblem9457(fmt.Sprintf("The request body contains malformed JSON at byte offset %d.", syntaxErr.Offset)).
+			WithExtension("offset", syntaxErr.Offset).
+			WithCause(err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return httperror.BadRequestProblem9457("The request body contains incomplete JSON.").WithCause(err)
+	case errors.Is(err, io.EOF):
+		return httperror.BadRequestProblem9457("The request body must not be empty.").WithCause(err)
+	case errors.As(err, &typeErr):
+		pointer := httperror.JSONPointer(fieldPath(typeErr.Field)...)
+		detail := "must be " + expected(typeErr.Type.Kind())
+		return httperror.NewValidationErrors().
+			Add(pointer, detail, "type").
+			ToRFC9457Error(http.StatusBadRequest, fmt.Sprintf("The request body contains an invalid value at byte offset %d.", typeErr.Offset)).
+			WithExtension("offset", typeErr.Offset).
+			WithCause(err)
+	case errors.As(err, &unmarshalErr):
+		// A programming error rather than a malformed request
+		return err
+	}
+
+	// encoding/json does not export an error type for unknown fields
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return httperror.BadRequestProblem9457(fmt.Sprintf("The request body contains the unknown member %s.", name)).
+			WithCause(err)
+	}
+	return err
+}
+
+// fieldPath splits the dotted field path of a json.UnmarshalTypeError into JSON Pointer tokens.
+func fieldPath(field string) []string {
+	if field == "" {
+		return nil
+	}
+	return strings.Split(field, ".")
+}
+
+// expected describes the JSON value expected in place of a Go value of the given kind.
+func expected(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "a valid value"
+}
+
+// tooLarge returns the problem for a body exceeding the maximum size.
+func tooLarge(maxBodySize int64) error {
+	return httperror.PayloadTooLargeProblem9457(fmt.Sprintf("The request body must not be larger than %d bytes.", maxBodySize)).
+		WithExtension("max-size", maxBodySize)
+}
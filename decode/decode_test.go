@@ -0,0 +1,191 @@
+package decode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+type item struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+type order struct {
+	Customer string `json:"customer"`
+	Items    []item `json:"items"`
+}
+
+func TestJSON(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"customer":"gopher","items":[{"sku":"A1","quantity":2}]}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var o order
+	if err := JSON(httptest.NewRecorder(), req, &o); err != nil {
+		t.Fatalf("JSON() = %v, want nil", err)
+	}
+	if o.Customer != "gopher" || len(o.Items) != 1 || o.Items[0].Quantity != 2 {
+		t.Errorf("Decoded %+v", o)
+	}
+}
+
+func TestJSON_Problems(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		opts           []Option
+		expectedStatus int
+		expectedDetail string
+		expectedExt    map[string]interface{}
+	}{
+		{
+			name:           "Unsupported media type",
+			contentType:    "text/plain",
+			body:           `{}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedDetail: "The request body must be JSON.",
+		},
+		{
+			name:           "Too large",
+			body:           `{"customer":"` + strings.Repeat("x", 64) + `"}`,
+			opts:           []Option{WithMaxBodySize(32)},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedDetail: "The request body must not be larger than 32 bytes.",
+			expectedExt:    map[string]interface{}{"max-size": float64(32)},
+		},
+		{
+			name:           "Empty body",
+			body:           ``,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: "The request body must not be empty.",
+		},
+		{
+			name:           "Syntax error",
+			body:           `{"customer": "gopher",}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: "The request body contains malformed JSON at byte offset 23.",
+			expectedExt:    map[string]interface{}{"offset": float64(23)},
+		},
+		{
+			name:           "Incomplete JSON",
+			body:           `{"customer": "gopher"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: "The request body contains incomplete JSON.",
+		},
+		{
+			name:           "Type error",
+			body:           `{"items":[{"sku":"A1","quantity":"two"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: "The request body contains an invalid value at byte offset 38.",
+			expectedExt: map[string]interface{}{
+				"offset": float64(38),
+				"errors": []interface{}{map[string]interface{}{"pointer": "#/items/0/quantity", "detail": "must be an integer", "code": "type"}},
+			},
+		},
+		{
+			name:           "Unknown field",
+			body:           `{"customer":"gopher","coupon":"FREE"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: `The request body contains the unknown member "coupon".`,
+		},
+		{
+			name:           "Trailing data",
+			body:           `{"customer":"gopher"} {"customer":"other"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: "The request body must contain a single JSON value.",
+			expectedExt:    map[string]interface{}{"offset": float64(21)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/orders", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			// Exercise http.MaxBytesReader rather than the Content-Length check
+			req.ContentLength = -1
+
+			var o order
+			err := JSON(httptest.NewRecorder(), req, &o, tt.opts...)
+
+			var problem *httperror.RFC9457Error
+			if !errors.As(err, &problem) {
+				t.Fatalf("Expected *httperror.RFC9457Error, got %T: %v", err, err)
+			}
+			if problem.Status != tt.expectedStatus {
+				t.Errorf("Status = %d, want %d", problem.Status, tt.expectedStatus)
+			}
+			if problem.Detail != tt.expectedDetail {
+				t.Errorf("Detail = %q, want %q", problem.Detail, tt.expectedDetail)
+			}
+
+			data, err := json.Marshal(problem)
+			if err != nil {
+				t.Fatalf("Failed to marshal problem: %v", err)
+			}
+			var members map[string]interface{}
+			if err := json.Unmarshal(data, &members); err != nil {
+				t.Fatalf("Failed to unmarshal problem: %v", err)
+			}
+			for key, value := range tt.expectedExt {
+				got, _ := json.Marshal(members[key])
+				want, _ := json.Marshal(value)
+				if string(got) != string(want) {
+					t.Errorf("%s = %s, want %s", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJSON_ContentLength(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"customer":"gopher"}`))
+
+	err := JSON(httptest.NewRecorder(), req, &order{}, WithMaxBodySize(8))
+	var problem *httperror.RFC9457Error
+	if !errors.As(err, &problem) || problem.Status != http.StatusRequestEntityTooLarge {
+		t.Errorf("JSON() = %v, want a 413 problem", err)
+	}
+}
+
+func TestJSON_AllowUnknownFields(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"customer":"gopher","coupon":"FREE"}`))
+
+	var o order
+	if err := JSON(httptest.NewRecorder(), req, &o, AllowUnknownFields()); err != nil {
+		t.Fatalf("JSON() = %v, want nil", err)
+	}
+	if o.Customer != "gopher" {
+		t.Errorf("Customer = %q, want gopher", o.Customer)
+	}
+}
+
+func TestJSON_InvalidTarget(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{}`))
+
+	err := JSON(httptest.NewRecorder(), req, order{})
+	var sc httperror.StatusCoder
+	if err == nil || errors.As(err, &sc) {
+		t.Errorf("JSON() = %v, want a programming error without a status code", err)
+	}
+}
+
+func TestCheckContentType(t *testing.T) {
+	for _, contentType := range []string{"", "application/json", "application/json; charset=utf-8", "application/merge-patch+json"} {
+		if err := CheckContentType(contentType); err != nil {
+			t.Errorf("CheckContentType(%q) = %v, want nil", contentType, err)
+		}
+	}
+	for _, contentType := range []string{"text/plain", "application/x-www-form-urlencoded", "invalid;;"} {
+		if err := CheckContentType(contentType); err == nil {
+			t.Errorf("CheckContentType(%q) = nil, want an error", contentType)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package decode
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/validate"
+)
+
+// textUnmarshalerType is the reflect.Type of encoding.TextUnmarshaler.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Params decodes path and query parameters into the fields of the struct pointed to by v.
+// Fields tagged `path:"name"` are set from pathValue(name), and fields tagged `query:"name"` from query.
+// Missing and empty parameters leave their fields unchanged.
+//
+// Fields may be strings, booleans, numbers, types implementing encoding.TextUnmarshaler, or pointers to them.
+// Query fields may also be slices of those types, which receive every value of a repeated parameter.
+// Values that cannot be converted are reported in a *httperror.ValidationErrors listing every such
+// parameter, located in the path or the query. Fields of unsupported types are programming errors
+// and are reported as a plain error.
+func Params(v interface{}, pathValue func(name string) string, query url.Values) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode: Params requires a non-nil pointer, got %T", v)
+	}
+	rv = rv.Elem()
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs := httperror.NewValidationErrors()
+	if err := setParams(errs, rv, pathValue, query); err != nil {
+		return err
+	}
+	return errs.Err()
+}
+
+// setParams sets the tagged fields of the struct rv, including those of embedded structs.
+func setParams(errs *httperror.ValidationErrors, rv reflect.Value, pathValue func(name string) string, query url.Values) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := setParams(errs, rv.Field(i), pathValue, query); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		var values []string
+		var name string
+		report := errs.AddParameter
+		if tag, ok := field.Tag.Lookup("path"); ok && pathValue != nil {
+			name = tag
+			report = errs.AddPathParameter
+			if value := pathValue(tag); value != "" {
+				values = []string{value}
+			}
+		} else if tag, ok := field.Tag.Lookup("query"); ok {
+			name = tag
+			values = query[tag]
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		ok, err := setValue(rv.Field(i), values)
+		if err != nil {
+			return fmt.Errorf("decode: field %s.%s: %w", rt.Name(), field.Name, err)
+		}
+		if !ok {
+			report(name, "must be "+expectedParam(field.Type), "type")
+		}
+	}
+	return nil
+}
+
+// setValue converts values into the field fv. It reports false if a value cannot be converted,
+// and returns an error if the field type is not supported.
+func setValue(fv reflect.Value, values []string) (bool, error) {
+	if fv.Kind() == reflect.Slice && !fv.Type().Implements(textUnmarshalerType) && fv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if ok, err := setValue(slice.Index(i), []string{value}); !ok || err != nil {
+				return ok, err
+			}
+		}
+		fv.Set(slice)
+		return true, nil
+	}
+
+	if fv.Kind() == reflect.Pointer {
+		elem := reflect.New(fv.Type().Elem())
+		ok, err := setValue(elem.Elem(), values)
+		if ok && err == nil {
+			fv.Set(elem)
+		}
+		return ok, err
+	}
+
+	value := values[0]
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)) == nil, nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, nil
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return false, nil
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return false, nil
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return false, nil
+		}
+		fv.SetFloat(n)
+	default:
+		return false, fmt.Errorf("unsupported parameter type %s", fv.Type())
+	}
+	return true, nil
+}
+
+// expectedParam describes the parameter value expected by a field of type t.
+func expectedParam(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		// Such as a time.Time, which is a struct but not an object
+		return "a valid value"
+	}
+	return expected(t.Kind())
+}
+
+// Request decodes a request into the value pointed to by v, as done by the typed handlers of the wrappers.
+// The JSON body, if any, is decoded with JSON; path and query parameters are then decoded with Params,
+// using pathValue to look up path parameters; finally, structs are validated with validate.Struct.
+// Failures from each step are returned as problems that handlers can return directly.
+func Request(writer http.ResponseWriter, request *http.Request, v interface{}, pathValue func(name string) string, opts ...Option) error {
+	if hasBody(request) {
+		if err := JSON(writer, request, v, opts...); err != nil {
+			return err
+		}
+	}
+	return finish(v, pathValue, request.URL.Query())
+}
+
+// finish decodes the parameters into v and validates it, merging parameter conversion failures
+// with validation failures so that the client learns about every invalid input at once.
+func finish(v interface{}, pathValue func(name string) string, query url.Values) error {
+	errs := httperror.NewValidationErrors()
+	if err := Params(v, pathValue, query); err != nil {
+		var paramErrs *httperror.ValidationErrors
+		if !errors.As(err, &paramErrs) {
+			return err
+		}
+		errs.Merge(paramErrs)
+	}
+
+	target := reflect.ValueOf(v)
+	for target.Kind() == reflect.Pointer && !target.IsNil() {
+		target = target.Elem()
+	}
+	if target.Kind() == reflect.Struct {
+		if err := validate.Struct(v); err != nil {
+			var validationErrs *httperror.ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				return err
+			}
+			errs.Merge(validationErrs)
+		}
+	}
+	return errs.Err()
+}
+
+// Values decodes an in-memory request body, path parameters and query parameters into v and validates it,
+// like Request. It is used by wrappers for frameworks that do not expose an *http.Request, such as fiber.
+// An empty body is skipped; a non-empty body must have a JSON contentType, or none.
+func Values(body []byte, contentType string, v interface{}, pathValue func(name string) string, query url.Values, opts ...Option) error {
+	if len(body) > 0 {
+		if err := CheckContentType(contentType); err != nil {
+			return err
+		}
+		if err := Bytes(body, v, opts...); err != nil {
+			return err
+		}
+	}
+	return finish(v, pathValue, query)
+}
+
+// hasBody reports whether the request has a body to decode.
+func hasBody(request *http.Request) bool {
+	if request.Body == nil || request.Body == http.NoBody {
+		return false
+	}
+	// A ContentLength of -1 means the length is unknown, such as for chunked requests
+	return request.ContentLength != 0
+}
@@ -0,0 +1,142 @@
+package decode
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+type page struct {
+	Page  int    `query:"page"`
+	Limit *uint8 `query:"limit"`
+}
+
+type listOrders struct {
+	page
+	Customer string    `path:"customer"`
+	Status   []string  `query:"status"`
+	Since    time.Time `query:"since"`
+	Express  bool      `query:"express"`
+	Min      float64   `query:"min"`
+	Note     string    `json:"note"`
+}
+
+func pathValues(values map[string]string) func(string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+func TestParams(t *testing.T) {
+	query := url.Values{
+		"page":    {"2"},
+		"limit":   {"50"},
+		"status":  {"open", "shipped"},
+		"since":   {"2024-01-02T03:04:05Z"},
+		"express": {"true"},
+		"min":     {"9.5"},
+		"note":    {"ignored"},
+	}
+
+	var o listOrders
+	if err := Params(&o, pathValues(map[string]string{"customer": "gopher"}), query); err != nil {
+		t.Fatalf("Params() = %v, want nil", err)
+	}
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if o.Customer != "gopher" || o.Page != 2 || o.Limit == nil || *o.Limit != 50 || !o.Express || o.Min != 9.5 || !o.Since.Equal(since) {
+		t.Errorf("Decoded %+v", o)
+	}
+	if !reflect.DeepEqual(o.Status, []string{"open", "shipped"}) {
+		t.Errorf("Status = %v, want [open shipped]", o.Status)
+	}
+	if o.Note != "" {
+		t.Errorf("Note = %q, want it to be left unset", o.Note)
+	}
+}
+
+func TestParams_Invalid(t *testing.T) {
+	query := url.Values{"page": {"two"}, "limit": {"300"}, "since": {"yesterday"}}
+
+	err := Params(&listOrders{}, nil, query)
+	var errs *httperror.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected *httperror.ValidationErrors, got %T: %v", err, err)
+	}
+	expected := []httperror.FieldError{
+		{Parameter: "page", In: "query", Detail: "must be an integer", Code: "type"},
+		{Parameter: "limit", In: "query", Detail: "must be a non-negative integer", Code: "type"},
+		{Parameter: "since", In: "query", Detail: "must be a valid value", Code: "type"},
+	}
+	if !reflect.DeepEqual(errs.Errors(), expected) {
+		t.Errorf("Errors() = %+v, want %+v", errs.Errors(), expected)
+	}
+}
+
+func TestParams_UnsupportedType(t *testing.T) {
+	var v struct {
+		Filter map[string]string `query:"filter"`
+	}
+
+	err := Params(&v, nil, url.Values{"filter": {"x"}})
+	var sc httperror.StatusCoder
+	if err == nil || errors.As(err, &sc) {
+		t.Errorf("Params() = %v, want a programming error without a status code", err)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	type createOrder struct {
+		Customer string `path:"customer" validate:"required"`
+		DryRun   bool   `query:"dry_run"`
+		Quantity int    `json:"quantity" validate:"min=1"`
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/customers/gopher/orders?dry_run=1", strings.NewReader(`{"quantity":2}`))
+
+		var o createOrder
+		if err := Request(httptest.NewRecorder(), req, &o, pathValues(map[string]string{"customer": "gopher"})); err != nil {
+			t.Fatalf("Request() = %v, want nil", err)
+		}
+		if o.Customer != "gopher" || !o.DryRun || o.Quantity != 2 {
+			t.Errorf("Decoded %+v", o)
+		}
+	})
+
+	t.Run("Reports every invalid input", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/customers//orders?dry_run=maybe", strings.NewReader(`{"quantity":0}`))
+
+		err := Request(httptest.NewRecorder(), req, &createOrder{}, pathValues(nil))
+		var errs *httperror.ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Expected *httperror.ValidationErrors, got %T: %v", err, err)
+		}
+		expected := []httperror.FieldError{
+			{Parameter: "dry_run", In: "query", Detail: "must be a boolean", Code: "type"},
+			{Parameter: "customer", In: "path", Detail: "is required", Code: "required"},
+			{Pointer: "#/quantity", Detail: "must be at least 1", Code: "min"},
+		}
+		if !reflect.DeepEqual(errs.Errors(), expected) {
+			t.Errorf("Errors() = %+v, want %+v", errs.Errors(), expected)
+		}
+	})
+
+	t.Run("Without body", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/orders?page=3", nil)
+
+		var p page
+		if err := Request(httptest.NewRecorder(), req, &p, nil); err != nil {
+			t.Fatalf("Request() = %v, want nil", err)
+		}
+		if p.Page != 3 {
+			t.Errorf("Page = %d, want 3", p.Page)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package httperror
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Localizable is implemented by errors whose message can be localized with a Catalog,
+// such as HttpError, RFC7807Error and RFC9457Error after calling WithMessageKey.
+// MessageKey returns an empty key if the message is not localizable.
+type Localizable interface {
+	MessageKey() (key string, args []interface{})
+}
+
+// Catalog holds localized problem titles and details, keyed by language tag and message key.
+// Titles are keyed by problem type URI, or by status code (for example "404") for problems
+// without a type, since RFC9457 allows the title of a problem type to change only for localization.
+// Details are keyed by the message key set with WithMessageKey.
+//
+// Messages are fmt format strings: verbs such as %s or %d are replaced with the arguments given to
+// WithMessageKey, and explicit argument indexes such as %[2]s allow translations to reorder arguments.
+// A literal percent sign must be written as "%%", even in messages without arguments.
+// It is safe for concurrent use.
+type Catalog struct {
+	mu        sync.RWMutex
+	languages []string
+	messages  map[string]map[string]string
+}
+
+// NewCatalog creates a new Catalog for the given language tags, such as "en" or "ko".
+// The first language is the default, used when the client accepts none of the languages.
+// Languages are offered to clients in the given order of preference.
+func NewCatalog(languages ...string) *Catalog {
+	c := &Catalog{
+		messages: make(map[string]map[string]string),
+	}
+	for _, lang := range languages {
+		c.addLanguage(lang)
+	}
+	return c
+}
+
+// addLanguage adds lang to the supported languages if it is not present yet.
+// The caller must hold the write lock or have exclusive access to the catalog.
+func (c *Catalog) addLanguage(lang string) map[string]string {
+	messages, ok := c.messages[lang]
+	if !ok {
+		messages = make(map[string]string)
+		c.messages[lang] = messages
+		c.languages = append(c.languages, lang)
+	}
+	return messages
+}
+
+// Set adds or replaces the message for key in the given language.
+// Languages that were not passed to NewCatalog are added with the lowest preference.
+func (c *Catalog) Set(lang, key, message string) *Catalog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addLanguage(lang)[key] = message
+	return c
+}
+
+// SetMessages adds or replaces the messages for the given keys in the given language.
+func (c *Catalog) SetMessages(lang string, messages map[string]string) *Catalog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.addLanguage(lang)
+	for key, message := range messages {
+		m[key] = message
+	}
+	return c
+}
+
+// Languages returns the supported language tags in order of preference.
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]string(nil), c.languages...)
+}
+
+// Message returns the message for key in the given language with its placeholders replaced by args.
+// Translations may use fewer placeholders than there are args: trailing args are dropped until the message
+// formats without error. A message that cannot be formatted with any number of args, such as one with a bare
+// "%" instead of "%%", is treated as missing. It reports false if the language has no usable message for key.
+func (c *Catalog) Message(lang, key string, args ...interface{}) (string, bool) {
+	c.mu.RLock()
+	message, ok := c.messages[lang][key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return "", false
+	}
+	for n := len(args); n >= 0; n-- {
+		// fmt reports missing, extra and malformed verbs inline, starting with "%!"
+		if s := fmt.Sprintf(message, args[:n]...); !strings.Contains(s, "%!") {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// Title returns the localized title of a problem with the given type URI and status code.
+// Problems of type "about:blank" or without a type are looked up by their status code.
+func (c *Catalog) Title(lang, typeURI string, status int) (string, bool) {
+	if typeURI == "" || typeURI == "about:blank" {
+		return c.Message(lang, strconv.Itoa(status))
+	}
+	return c.Message(lang, typeURI)
+}
@@ -0,0 +1,101 @@
+package httperror
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCatalog_Message(t *testing.T) {
+	c := NewCatalog("en", "ko").
+		Set("en", "user.not_found", "User %d was not found in %s").
+		Set("ko", "user.not_found", "%[2]s에서 사용자 %[1]d을(를) 찾을 수 없습니다").
+		Set("ko", "plain", "100%% 완료").
+		Set("en", "malformed", "100% done for %s").
+		Set("ko", "user.not_found.short", "사용자를 찾을 수 없습니다").
+		Set("ko", "user.not_found.id", "사용자 %d을(를) 찾을 수 없습니다").
+		Set("en", "progress", "100%% done, % d left").
+		Set("en", "progress.width", "%5.2f%% done in %*d steps")
+
+	tests := []struct {
+		name     string
+		lang     string
+		key      string
+		args     []interface{}
+		expected string
+		ok       bool
+	}{
+		{"English", "en", "user.not_found", []interface{}{42, "accounts"}, "User 42 was not found in accounts", true},
+		{"Reordered arguments", "ko", "user.not_found", []interface{}{42, "accounts"}, "accounts에서 사용자 42을(를) 찾을 수 없습니다", true},
+		{"No arguments", "ko", "plain", nil, "100% 완료", true},
+		{"Literal percent with arguments", "ko", "plain", []interface{}{42}, "100% 완료", true},
+		{"Bare percent before a letter", "en", "malformed", []interface{}{"x"}, "", false},
+		{"Translation without placeholders", "ko", "user.not_found.short", []interface{}{42, "accounts"}, "사용자를 찾을 수 없습니다", true},
+		{"Translation with fewer placeholders", "ko", "user.not_found.id", []interface{}{42, "accounts"}, "사용자 42을(를) 찾을 수 없습니다", true},
+		{"Escaped percent before a space flag", "en", "progress", []interface{}{3, "extra"}, "100% done,  3 left", true},
+		{"Escaped percent between width and precision", "en", "progress.width", []interface{}{99.5, 3, 7, "extra"}, "99.50% done in   7 steps", true},
+		{"Missing key", "en", "plain", nil, "", false},
+		{"Missing language", "fr", "user.not_found", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Message(tt.lang, tt.key, tt.args...)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("Message(%q, %q) = %q, %v, want %q, %v", tt.lang, tt.key, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCatalog_Title(t *testing.T) {
+	c := NewCatalog("ko").SetMessages("ko", map[string]string{
+		"404": "찾을 수 없음",
+		"https://example.com/probs/out-of-credit": "크레딧이 부족합니다",
+	})
+
+	if got, _ := c.Title("ko", "about:blank", 404); got != "찾을 수 없음" {
+		t.Errorf("Title for about:blank = %q, want %q", got, "찾을 수 없음")
+	}
+	if got, _ := c.Title("ko", "", 404); got != "찾을 수 없음" {
+		t.Errorf("Title without type = %q, want %q", got, "찾을 수 없음")
+	}
+	if got, _ := c.Title("ko", "https://example.com/probs/out-of-credit", 403); got != "크레딧이 부족합니다" {
+		t.Errorf("Title for type = %q, want %q", got, "크레딧이 부족합니다")
+	}
+	if _, ok := c.Title("ko", "https://example.com/probs/unknown", 404); ok {
+		t.Error("Title for unknown type should not fall back to the status code")
+	}
+}
+
+func TestCatalog_Languages(t *testing.T) {
+	c := NewCatalog("en", "ko").Set("ja", "key", "message").Set("en", "key", "message")
+
+	if got := c.Languages(); !reflect.DeepEqual(got, []string{"en", "ko", "ja"}) {
+		t.Errorf("Languages() = %v, want [en ko ja]", got)
+	}
+}
+
+func TestMessageKey_Conversions(t *testing.T) {
+	p := NotFoundProblem9457("User 42 not found").WithMessageKey("user.not_found", 42)
+
+	tests := []struct {
+		name string
+		l    Localizable
+	}{
+		{"RFC9457Error", p},
+		{"ToHttpError", p.ToHttpError()},
+		{"ToXMLHttpError", p.ToXMLHttpError()},
+		{"ToRFC7807Error", p.ToRFC7807Error()},
+		{"RFC7807Error.ToRFC9457Error", p.ToRFC7807Error().ToRFC9457Error()},
+		{"ProblemDetails", p.ToHttpError().ProblemDetails()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, args := tt.l.MessageKey()
+			if key != "user.not_found" || !reflect.DeepEqual(args, []interface{}{42}) {
+				t.Errorf("MessageKey() = %q, %v, want %q, [42]", key, args, "user.not_found")
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package httperror
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gosuda/httpwrap/internal/negotiate"
+)
+
+// docsTemplates renders the HTML documentation pages served by DocsHandler.
+var docsTemplates = template.Must(template.New("docs").Parse(`
+{{- define "index" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Problem Types</title>
+</head>
+<body>
+<h1>Problem Types</h1>
+<table>
+<thead><tr><th>Type</th><th>Title</th><th>Status</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td><a href="{{.Link}}">{{.URI}}</a></td><td>{{.Title}}</td><td>{{.Status}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+{{end}}
+{{- define "type" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<dl>
+<dt>Type</dt><dd><code>{{.URI}}</code></dd>
+<dt>Status</dt><dd>{{.Status}} {{.StatusText}}</dd>
+</dl>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+</body>
+</html>
+{{end}}`))
+
+// docsEntry is the view of a ProblemType used by the HTML templates.
+type docsEntry struct {
+	ProblemType
+	Link       string
+	StatusText string
+}
+
+// DocsHandler serves human-readable documentation for the problem types of a Registry,
+// so that type URIs resolve to documentation as recommended by RFC9457 Section 3.1.1.
+// It serves an index of all registered types at the path of its base URL and one page per type
+// whose type URI lies below the base URL. Pages are HTML by default; clients that prefer
+// application/json receive the ProblemType metadata as JSON instead.
+type DocsHandler struct {
+	registry *Registry
+	scheme   string // Scheme of the base URL, empty for relative type URIs
+	host     string // Host of the base URL, empty for relative type URIs
+	prefix   string // Path of the base URL, at which the index is served
+}
+
+// NewDocsHandler creates a DocsHandler for the problem types of registry whose type URIs lie below base,
+// serving the index at the path of base. For example, with base "https://example.com/probs" the type
+// "https://example.com/probs/out-of-credit" is documented at "/probs/out-of-credit", while types of
+// other hosts, such as "https://other.example/probs/out-of-credit", are not served.
+// A base without scheme and host, such as "/probs", documents relative type URIs only.
+// If registry is nil, DefaultRegistry is used.
+func NewDocsHandler(registry *Registry, base string) *DocsHandler {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	h := &DocsHandler{registry: registry}
+	if u, err := url.Parse(base); err == nil && u.IsAbs() {
+		h.scheme = strings.ToLower(u.Scheme)
+		h.host = strings.ToLower(u.Host)
+		base = u.Path
+	}
+	h.prefix = "/" + strings.Trim(base, "/")
+	return h
+}
+
+// ServeHTTP implements the http.Handler interface.
+// Errors are written as application/problem+json documents.
+func (h *DocsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.Serve(w, r); err != nil {
+		var problem *RFC9457Error
+		if !errors.As(err, &problem) {
+			problem = InternalServerErrorProblem9457(http.StatusText(http.StatusInternalServerError))
+		}
+		contentType, body, _ := problem.RenderProblem()
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(problem.Status)
+		w.Write(body)
+	}
+}
+
+// Serve is the error-returning form of ServeHTTP. It can be registered directly as a handler
+// on the httpwrap and chiwrap wrappers so that errors go through their error pipeline.
+// Fiber applications can mount ServeHTTP through Fiber's adaptor middleware instead.
+func (h *DocsHandler) Serve(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		return MethodNotAllowedProblem9457("Problem type documentation only supports GET and HEAD")
+	}
+
+	path := "/" + strings.Trim(r.URL.Path, "/")
+	if path == h.prefix {
+		return h.serveIndex(w, r)
+	}
+
+	for _, pt := range h.registry.Types() {
+		if h.localPath(pt.URI) == path {
+			return h.serveType(w, r, pt)
+		}
+	}
+	return NotFoundProblem9457("No problem type is documented at " + r.URL.Path)
+}
+
+// serveIndex writes the list of all registered problem types.
+func (h *DocsHandler) serveIndex(w http.ResponseWriter, r *http.Request) error {
+	types := h.registry.Types()
+	if wantsJSON(r) {
+		return writeDocsJSON(w, types)
+	}
+
+	entries := make([]docsEntry, 0, len(types))
+	for _, pt := range types {
+		entries = append(entries, h.entry(pt))
+	}
+	return writeDocsHTML(w, "index", entries)
+}
+
+// serveType writes the documentation of a single problem type.
+func (h *DocsHandler) serveType(w http.ResponseWriter, r *http.Request, pt ProblemType) error {
+	if wantsJSON(r) {
+		return writeDocsJSON(w, pt)
+	}
+	return writeDocsHTML(w, "type", h.entry(pt))
+}
+
+// entry builds the template view of a problem type.
+// Types documented by this handler link to their local page; other types link to their type URI.
+func (h *DocsHandler) entry(pt ProblemType) docsEntry {
+	link := pt.URI
+	if local := h.localPath(pt.URI); local != "" {
+		link = local
+	}
+	return docsEntry{
+		ProblemType: pt,
+		Link:        link,
+		StatusText:  http.StatusText(pt.Status),
+	}
+}
+
+// localPath returns the path at which the type URI is documented by this handler,
+// or "" if the type URI does not lie below the handler's base URL.
+func (h *DocsHandler) localPath(typeURI string) string {
+	u, err := url.Parse(typeURI)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	if strings.ToLower(u.Scheme) != h.scheme || strings.ToLower(u.Host) != h.host {
+		return ""
+	}
+	path := "/" + strings.Trim(u.Path, "/")
+	prefix := strings.TrimSuffix(h.prefix, "/") + "/"
+	if !strings.HasPrefix(path, prefix) {
+		return ""
+	}
+	return path
+}
+
+// wantsJSON reports whether the client prefers JSON over HTML.
+func wantsJSON(r *http.Request) bool {
+	return negotiate.ContentType(r.Header.Get("Accept"), "text/html", "application/json") == "application/json"
+}
+
+// writeDocsJSON writes v as the JSON documentation of one or all problem types.
+// It returns a 500 problem if v cannot be encoded, before anything is written.
+func writeDocsJSON(w http.ResponseWriter, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return InternalServerErrorProblem9457("Failed to encode problem type documentation").WithCause(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Vary", "Accept")
+	w.Write(body)
+	return nil
+}
+
+// writeDocsHTML writes the HTML documentation page rendered by the named docs template with data.
+// The page is rendered into a buffer first, so a template error is returned as a 500 problem before anything is written.
+func writeDocsHTML(w http.ResponseWriter, name string, data interface{}) error {
+	var buf strings.Builder
+	if err := docsTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		return InternalServerErrorProblem9457("Failed to render problem type documentation").WithCause(err)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Add("Vary", "Accept")
+	w.Write([]byte(buf.String()))
+	return nil
+}
@@ -0,0 +1,167 @@
+package httperror
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newDocsRegistry() *Registry {
+	r := NewRegistry()
+	r.MustRegister(ProblemType{
+		URI:         "https://example.com/probs/out-of-credit",
+		Title:       "You do not have enough credit.",
+		Status:      http.StatusForbidden,
+		Description: "The account balance is too low <for> the requested operation.",
+	})
+	r.MustRegister(ProblemType{
+		URI:    "https://other.example/probs/out-of-stock",
+		Title:  "The item is out of stock.",
+		Status: http.StatusConflict,
+	})
+	r.MustRegister(ProblemType{
+		URI:    "https://httpstatuses.io/404",
+		Title:  "Not Found",
+		Status: http.StatusNotFound,
+	})
+	return r
+}
+
+func TestDocsHandler_Index(t *testing.T) {
+	h := NewDocsHandler(newDocsRegistry(), "https://example.com/probs/")
+
+	req := httptest.NewRequest("GET", "/probs", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected HTML content type, got %s", ct)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `href="/probs/out-of-credit"`) {
+		t.Errorf("Expected local link to the out-of-credit page, got %s", body)
+	}
+	if !strings.Contains(body, `href="https://other.example/probs/out-of-stock"`) {
+		t.Errorf("Expected external link for a type URI of another host, got %s", body)
+	}
+	if !strings.Contains(body, `href="https://httpstatuses.io/404"`) {
+		t.Errorf("Expected external link for a foreign type URI, got %s", body)
+	}
+}
+
+func TestDocsHandler_Type(t *testing.T) {
+	h := NewDocsHandler(newDocsRegistry(), "https://example.com/probs")
+
+	req := httptest.NewRequest("GET", "/probs/out-of-credit", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "<h1>You do not have enough credit.</h1>") {
+		t.Errorf("Expected title heading, got %s", body)
+	}
+	if !strings.Contains(body, "&lt;for&gt;") {
+		t.Errorf("Expected description to be escaped, got %s", body)
+	}
+}
+
+func TestDocsHandler_JSON(t *testing.T) {
+	h := NewDocsHandler(newDocsRegistry(), "https://example.com/probs")
+
+	req := httptest.NewRequest("GET", "/probs/out-of-credit", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Expected JSON content type, got %s", ct)
+	}
+
+	var pt ProblemType
+	if err := json.Unmarshal(w.Body.Bytes(), &pt); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if pt.URI != "https://example.com/probs/out-of-credit" || pt.Status != http.StatusForbidden {
+		t.Errorf("Unexpected problem type %+v", pt)
+	}
+
+	req = httptest.NewRequest("GET", "/probs", nil)
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var types []ProblemType
+	if err := json.Unmarshal(w.Body.Bytes(), &types); err != nil {
+		t.Fatalf("Failed to unmarshal index: %v", err)
+	}
+	if len(types) != 3 {
+		t.Errorf("Expected 3 problem types, got %d", len(types))
+	}
+}
+
+func TestDocsHandler_Errors(t *testing.T) {
+	h := NewDocsHandler(newDocsRegistry(), "https://example.com/probs")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"Unknown type", "GET", "/probs/unknown", http.StatusNotFound},
+		{"Type of another host", "GET", "/probs/out-of-stock", http.StatusNotFound},
+		{"Unsupported method", "POST", "/probs", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Expected problem content type, got %s", ct)
+			}
+		})
+	}
+}
+
+func TestDocsHandler_RelativeTypes(t *testing.T) {
+	r := newDocsRegistry()
+	r.MustRegister(ProblemType{
+		URI:    "/probs/rate-limited",
+		Title:  "Too many requests were sent.",
+		Status: http.StatusTooManyRequests,
+	})
+	h := NewDocsHandler(r, "/probs")
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/probs/rate-limited", http.StatusOK},
+		{"/probs/out-of-credit", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
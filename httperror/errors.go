@@ -7,6 +7,36 @@ import (
 	"strconv"
 )
 
+// StatusCoder is implemented by errors that carry an HTTP status code.
+// The wrappers discover it with errors.As, so any error in the chain implementing it
+// determines the status code of the response.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// ErrorMessager is implemented by errors that provide a message which is safe to send to clients.
+type ErrorMessager interface {
+	ErrorMessage() string
+}
+
+// ProblemRenderer is implemented by errors that can render their own response body.
+// Errors that also implement ProblemDetailer, such as RFC7807Error and RFC9457Error, are rendered
+// from their problem details in the negotiated format instead.
+type ProblemRenderer interface {
+	StatusCoder
+	// RenderProblem returns the content type and body of the response.
+	RenderProblem() (contentType string, body []byte, err error)
+}
+
+// ProblemDetailer is implemented by errors that can describe themselves as an RFC9457 problem details object.
+// Renderers use it to serialize the problem in whichever format is negotiated with the client,
+// such as application/problem+json, application/problem+xml or HTML.
+// ProblemDetails returns nil if the error has no problem details representation.
+type ProblemDetailer interface {
+	StatusCoder
+	ProblemDetails() *RFC9457Error
+}
+
 // HttpError represents an HTTP error with a status code, message, and optional content type.
 // It implements the error interface and provides methods to retrieve the status code and error message.
 // The ContentType field allows customization of the response content type for different error formats.
@@ -14,6 +44,11 @@ type HttpError struct {
 	Code        int    `json:"code"`                   // HTTP status code
 	Message     string `json:"message"`                // Human-readable error message
 	ContentType string `json:"content_type,omitempty"` // Optional content type for the error response
+
+	cause       error         // Underlying error; never serialized into the response
+	problem     *RFC9457Error // Problem the message was serialized from, if created by ToHttpError or ToXMLHttpError
+	messageKey  string        // Catalog key of the localized message
+	messageArgs []interface{} // Placeholder arguments of the localized message
 }
 
 // New creates a new HttpError with the specified status code, message, and optional content type.
@@ -30,12 +65,50 @@ func New(code int, message string, contentType ...string) *HttpError {
 	}
 }
 
+// WithCause sets the underlying error that caused this HttpError and returns the error for method chaining.
+// The cause is available through errors.Is and errors.As and is included in Error(),
+// but it is never written to the response body.
+func (e *HttpError) WithCause(cause error) *HttpError {
+	e.cause = cause
+	return e
+}
+
+// WithMessageKey sets the Catalog key and placeholder arguments of the localized message and returns the error
+// for method chaining. Renderers configured with a Catalog replace Message with the message for the key in the
+// language negotiated from the request's Accept-Language header; Message is used when no translation exists.
+func (e *HttpError) WithMessageKey(key string, args ...interface{}) *HttpError {
+	e.messageKey = key
+	e.messageArgs = args
+	return e
+}
+
+// MessageKey returns the Catalog key and placeholder arguments of the localized message, implementing the Localizable interface.
+func (e *HttpError) MessageKey() (key string, args []interface{}) {
+	return e.messageKey, e.messageArgs
+}
+
 // Error returns a string representation of the HttpError in the format "code: message".
+// If a cause is set, it is appended as "code: message: cause".
 // This method implements the error interface.
 func (e *HttpError) Error() string {
+	if e.cause != nil {
+		return strconv.Itoa(e.Code) + ": " + e.Message + ": " + e.cause.Error()
+	}
 	return strconv.Itoa(e.Code) + ": " + e.Message
 }
 
+// Unwrap returns the underlying cause of the HttpError, if any.
+func (e *HttpError) Unwrap() error {
+	return e.cause
+}
+
+// ProblemDetails returns the problem details the HttpError was created from by ToHttpError or ToXMLHttpError,
+// or nil if the HttpError was created directly. This implements the ProblemDetailer interface,
+// allowing renderers to serialize the original problem in a format negotiated with the client.
+func (e *HttpError) ProblemDetails() *RFC9457Error {
+	return e.problem
+}
+
 // StatusCode returns the HTTP status code associated with this error.
 func (e *HttpError) StatusCode() int {
 	return e.Code
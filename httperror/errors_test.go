@@ -1,6 +1,9 @@
 package httperror
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -156,3 +159,68 @@ func TestErrorHelperFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusCoder_ErrorsAs(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{
+			name: "HttpError",
+			err:  fmt.Errorf("wrapped: %w", NotFound("missing")),
+			code: 404,
+		},
+		{
+			name: "RFC7807Error",
+			err:  fmt.Errorf("wrapped: %w", ConflictProblem7807("conflict")),
+			code: 409,
+		},
+		{
+			name: "RFC9457Error",
+			err:  fmt.Errorf("wrapped: %w", TooManyRequestsProblem9457("slow down")),
+			code: 429,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sc StatusCoder
+			if !errors.As(tt.err, &sc) {
+				t.Fatal("Expected errors.As to find a StatusCoder")
+			}
+			if sc.StatusCode() != tt.code {
+				t.Errorf("Expected status code %d, got %d", tt.code, sc.StatusCode())
+			}
+		})
+	}
+}
+
+func TestHttpError_WithCause(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	e := NotFound("User not found").WithCause(cause)
+
+	if !errors.Is(e, cause) {
+		t.Error("Expected errors.Is to find the cause")
+	}
+
+	if got := e.Unwrap(); got != cause {
+		t.Errorf("HttpError.Unwrap() = %v, want %v", got, cause)
+	}
+
+	if got, want := e.Error(), "404: User not found: sql: no rows in result set"; got != want {
+		t.Errorf("HttpError.Error() = %v, want %v", got, want)
+	}
+
+	if got := e.ErrorMessage(); got != "User not found" {
+		t.Errorf("HttpError.ErrorMessage() = %v, want %v", got, "User not found")
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Failed to marshal HttpError: %v", err)
+	}
+	if got, want := string(data), `{"code":404,"message":"User not found"}`; got != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package httperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is an error recovered from a panic in a handler.
+// Renderers respond to it with a generic 500 Internal Server Error problem without leaking the panic value
+// or the stack trace to the client, even if the panic value is an error carrying a status code, since a
+// panic is never an intended response. Custom renderers should check for it before looking for a StatusCoder.
+type PanicError struct {
+	Value interface{} // Value passed to panic
+	Stack []byte      // Stack trace of the panicking goroutine, as formatted by runtime/debug.Stack
+}
+
+// NewPanicError creates a PanicError for the value returned by recover.
+// It must be called from the deferred function that recovered, so that the stack trace
+// still contains the frames of the panicking handler.
+func NewPanicError(value interface{}) *PanicError {
+	return &PanicError{
+		Value: value,
+		Stack: debug.Stack(),
+	}
+}
+
+// Error returns the panic value in the format "panic: value". The stack trace is not included.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, so that errors.Is and errors.As can inspect it
+// when logging or reporting the panic.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// IsAbortPanic reports whether value, as returned by recover, is http.ErrAbortHandler.
+// net/http handlers panic with it to abort a response, and it must be re-panicked
+// rather than rendered so that the server can abort the connection silently.
+func IsAbortPanic(value interface{}) bool {
+	err, ok := value.(error)
+	return ok && errors.Is(err, http.ErrAbortHandler)
+}
@@ -0,0 +1,48 @@
+package httperror
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPanicError(t *testing.T) {
+	var err error
+	func() {
+		defer func() {
+			err = NewPanicError(recover())
+		}()
+		panic(io.ErrUnexpectedEOF)
+	}()
+
+	if err.Error() != "panic: unexpected EOF" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "panic: unexpected EOF")
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Expected errors.Is to find the panic value")
+	}
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		t.Error("PanicError must not carry a status code")
+	}
+
+	var pe *PanicError
+	if !errors.As(err, &pe) || !strings.Contains(string(pe.Stack), "TestPanicError") {
+		t.Errorf("Expected the stack trace to contain the panicking function, got %s", pe.Stack)
+	}
+}
+
+func TestIsAbortPanic(t *testing.T) {
+	if !IsAbortPanic(http.ErrAbortHandler) {
+		t.Error("Expected http.ErrAbortHandler to be an abort panic")
+	}
+	if !IsAbortPanic(fmt.Errorf("aborting: %w", http.ErrAbortHandler)) {
+		t.Error("Expected a wrapped http.ErrAbortHandler to be an abort panic")
+	}
+	if IsAbortPanic("boom") || IsAbortPanic(errors.New("boom")) {
+		t.Error("Expected other values not to be abort panics")
+	}
+}
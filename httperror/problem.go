@@ -0,0 +1,312 @@
+package httperror
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// problemFields holds the standard members shared by RFC7807Error and RFC9457Error.
+type problemFields struct {
+	Type     *string
+	Title    *string
+	Status   *int
+	Detail   *string
+	Instance *string
+}
+
+// unmarshalProblem decodes a JSON problem document into its standard members and extension members.
+// As required by RFC9457 Section 3.1, a standard member whose value has the wrong JSON type is ignored
+// instead of failing the decode. Standard members that are absent or null are left nil.
+// Every other top-level member is returned as an extension member.
+func unmarshalProblem(data []byte) (problemFields, map[string]interface{}, error) {
+	var fields problemFields
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return fields, nil, err
+	}
+
+	var extensions map[string]interface{}
+	for key, raw := range members {
+		switch key {
+		case "type":
+			fields.Type = decodeMember[string](raw)
+		case "title":
+			fields.Title = decodeMember[string](raw)
+		case "status":
+			fields.Status = decodeMember[int](raw)
+		case "detail":
+			fields.Detail = decodeMember[string](raw)
+		case "instance":
+			fields.Instance = decodeMember[string](raw)
+		default:
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fields, nil, err
+			}
+			if extensions == nil {
+				extensions = make(map[string]interface{})
+			}
+			extensions[key] = value
+		}
+	}
+
+	return fields, extensions, nil
+}
+
+// decodeMember decodes a standard member, returning nil if it is null or has the wrong JSON type.
+func decodeMember[T any](raw json.RawMessage) *T {
+	if string(raw) == "null" {
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+	return &value
+}
+
+// assign stores the decoded value in dst if it is present.
+func assign[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// ProblemXMLNamespace is the XML namespace of problem details documents, as defined in RFC9457 Appendix B.
+const ProblemXMLNamespace = "urn:ietf:rfc:7807"
+
+// marshalProblemXML encodes a problem details object as an XML document as defined in RFC9457 Appendix B.
+// Extension members are encoded as child elements: arrays become a sequence of <i> elements,
+// objects become nested elements, null becomes an empty element, and other values become text content.
+// Extension members whose names are not valid XML names cannot be represented and make it fail.
+func marshalProblemXML(e *xml.Encoder, typ, title string, status int, detail, instance string, extensions map[string]interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Space: ProblemXMLNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	members := []struct {
+		name  string
+		value string
+	}{
+		{"type", typ},
+		{"title", title},
+		{"status", ""},
+		{"detail", detail},
+		{"instance", instance},
+	}
+	if status != 0 {
+		members[2].value = strconv.Itoa(status)
+	}
+	for _, m := range members {
+		if m.value == "" {
+			continue
+		}
+		if err := e.EncodeElement(m.value, xml.StartElement{Name: xml.Name{Local: m.name}}); err != nil {
+			return err
+		}
+	}
+
+	if len(extensions) > 0 {
+		// Normalize extension values to the JSON data model, so that structs, typed slices
+		// and maps are encoded the same way as in the JSON representation
+		data, err := json.Marshal(extensions)
+		if err != nil {
+			return err
+		}
+		var normalized map[string]interface{}
+		if err := json.Unmarshal(data, &normalized); err != nil {
+			return err
+		}
+		if err := encodeXMLMembers(e, normalized); err != nil {
+			return err
+		}
+	}
+
+	if err := e.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// encodeXMLMembers encodes the members of an object as child elements, sorted by name.
+func encodeXMLMembers(e *xml.Encoder, members map[string]interface{}) error {
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !validXMLName(k) {
+			return fmt.Errorf("extension member %q cannot be represented as an XML element", k)
+		}
+		if err := encodeXMLValue(e, k, members[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeXMLValue encodes a single value of the JSON data model as an element with the given name.
+func encodeXMLValue(e *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	var err error
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		err = encodeXMLMembers(e, v)
+	case []interface{}:
+		for _, item := range v {
+			if err = encodeXMLValue(e, "i", item); err != nil {
+				break
+			}
+		}
+	case float64:
+		err = e.EncodeToken(xml.CharData(strconv.FormatFloat(v, 'f', -1, 64)))
+	case bool:
+		err = e.EncodeToken(xml.CharData(strconv.FormatBool(v)))
+	case string:
+		err = e.EncodeToken(xml.CharData(v))
+	default:
+		err = fmt.Errorf("unsupported extension value of type %T", value)
+	}
+	if err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// validXMLName reports whether name can be used as an XML element name.
+func validXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// unmarshalProblemXML decodes an XML problem details document into its standard members and extension members.
+// As with JSON, a standard member with an invalid value is ignored. Extension members are decoded as strings,
+// as arrays ([]interface{}) when all their child elements are <i> elements, or as objects
+// (map[string]interface{}) when they have other child elements. XML carries no type information,
+// so numbers and booleans in extension members are returned as strings, and null values,
+// encoded as empty elements, are returned as empty strings.
+func unmarshalProblemXML(d *xml.Decoder, start xml.StartElement) (problemFields, map[string]interface{}, error) {
+	var fields problemFields
+	if start.Name.Local != "problem" {
+		return fields, nil, fmt.Errorf("expected <problem> element, got <%s>", start.Name.Local)
+	}
+
+	value, err := decodeXMLValue(d)
+	if err != nil {
+		return fields, nil, err
+	}
+	members, ok := value.(map[string]interface{})
+	if !ok {
+		return fields, nil, nil
+	}
+
+	var extensions map[string]interface{}
+	for key, v := range members {
+		text, isText := v.(string)
+		switch key {
+		case "type", "title", "detail", "instance":
+			if !isText {
+				continue
+			}
+			switch key {
+			case "type":
+				fields.Type = &text
+			case "title":
+				fields.Title = &text
+			case "detail":
+				fields.Detail = &text
+			case "instance":
+				fields.Instance = &text
+			}
+		case "status":
+			if status, err := strconv.Atoi(strings.TrimSpace(text)); isText && err == nil {
+				fields.Status = &status
+			}
+		default:
+			if extensions == nil {
+				extensions = make(map[string]interface{})
+			}
+			extensions[key] = v
+		}
+	}
+	return fields, extensions, nil
+}
+
+// decodeXMLValue decodes the content of the current element up to and including its end element.
+func decodeXMLValue(d *xml.Decoder) (interface{}, error) {
+	var text strings.Builder
+	var children []struct {
+		name  string
+		value interface{}
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLValue(d)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, struct {
+				name  string
+				value interface{}
+			}{t.Name.Local, child})
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(children) == 0 {
+				return text.String(), nil
+			}
+
+			isArray := true
+			for _, c := range children {
+				if c.name != "i" {
+					isArray = false
+					break
+				}
+			}
+			if isArray {
+				items := make([]interface{}, 0, len(children))
+				for _, c := range children {
+					items = append(items, c.value)
+				}
+				return items, nil
+			}
+
+			object := make(map[string]interface{}, len(children))
+			for _, c := range children {
+				object[c.name] = c.value
+			}
+			return object, nil
+		}
+	}
+}
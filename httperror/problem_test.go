@@ -0,0 +1,190 @@
+package httperror
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRFC9457Error_MarshalXML(t *testing.T) {
+	p := NewRFC9457ErrorWithType(403, "https://example.com/probs/out-of-credit", "You do not have enough credit.", "Your current balance is 30, but that costs 50.").
+		WithInstance("https://example.net/account/12345/msgs/abc").
+		WithExtension("balance", 30).
+		WithExtension("accounts", []string{"https://example.net/account/12345", "https://example.net/account/67890"})
+
+	data, err := xml.Marshal(p)
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+
+	want := `<problem xmlns="urn:ietf:rfc:7807">` +
+		`<type>https://example.com/probs/out-of-credit</type>` +
+		`<title>You do not have enough credit.</title>` +
+		`<status>403</status>` +
+		`<detail>Your current balance is 30, but that costs 50.</detail>` +
+		`<instance>https://example.net/account/12345/msgs/abc</instance>` +
+		`<accounts><i>https://example.net/account/12345</i><i>https://example.net/account/67890</i></accounts>` +
+		`<balance>30</balance>` +
+		`</problem>`
+	if string(data) != want {
+		t.Errorf("xml.Marshal() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestRFC9457Error_MarshalXML_Escaping(t *testing.T) {
+	p := NewRFC9457Error(400, "Bad Request", "<script>alert(1)</script> & more").
+		WithExtension("nested", map[string]interface{}{"field": "email", "valid": false}).
+		WithExtension("empty", nil)
+
+	data, err := xml.Marshal(p)
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "<script>") {
+		t.Errorf("Detail must be escaped, got %s", data)
+	}
+	if !strings.Contains(string(data), "<nested><field>email</field><valid>false</valid></nested>") {
+		t.Errorf("Expected nested object elements, got %s", data)
+	}
+	if !strings.Contains(string(data), "<empty></empty>") {
+		t.Errorf("Expected empty element for nil value, got %s", data)
+	}
+}
+
+func TestRFC9457Error_MarshalXML_InvalidExtensionName(t *testing.T) {
+	p := NewRFC9457Error(400, "Bad Request", "detail").WithExtension("not a name", "value")
+
+	if _, err := xml.Marshal(p); err == nil {
+		t.Error("xml.Marshal() should fail for an extension name that is not a valid XML name")
+	}
+
+	he := p.ToXMLHttpError()
+	if he.ContentType != "application/problem+json" {
+		t.Fatalf("ToXMLHttpError() should fall back to application/problem+json, got %s", he.ContentType)
+	}
+	var decoded RFC9457Error
+	if err := json.Unmarshal([]byte(he.Message), &decoded); err != nil {
+		t.Fatalf("Fallback body is not a JSON problem: %v", err)
+	}
+	if decoded.Extensions["not a name"] != "value" {
+		t.Errorf("Fallback body should keep the extension, got %v", decoded.Extensions)
+	}
+	if he.ProblemDetails() != p {
+		t.Error("Fallback HttpError should keep the problem details")
+	}
+
+	he7807 := NewRFC7807Error(400, "Bad Request", "detail").WithExtension("1st", "value").ToXMLHttpError()
+	if he7807.ContentType != "application/problem+json" {
+		t.Errorf("RFC7807 ToXMLHttpError() should fall back to application/problem+json, got %s", he7807.ContentType)
+	}
+}
+
+func TestRFC9457Error_UnmarshalXML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<problem xmlns="urn:ietf:rfc:7807">
+  <type>https://example.com/probs/out-of-credit</type>
+  <title>You do not have enough credit.</title>
+  <status>forbidden</status>
+  <detail>Your current balance is 30, but that costs 50.</detail>
+  <balance>30</balance>
+  <accounts>
+    <i>https://example.net/account/12345</i>
+    <i>https://example.net/account/67890</i>
+  </accounts>
+  <limits><daily>100</daily></limits>
+</problem>`
+
+	var p RFC9457Error
+	if err := xml.Unmarshal([]byte(input), &p); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+
+	want := RFC9457Error{
+		Type:   "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Detail: "Your current balance is 30, but that costs 50.",
+		Extensions: map[string]interface{}{
+			"balance":  "30",
+			"accounts": []interface{}{"https://example.net/account/12345", "https://example.net/account/67890"},
+			"limits":   map[string]interface{}{"daily": "100"},
+		},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("xml.Unmarshal() = %+v, want %+v", p, want)
+	}
+}
+
+func TestRFC9457Error_XMLRoundTrip(t *testing.T) {
+	original := NotFoundProblem9457("User 123 not found").
+		WithInstance("/api/users/123").
+		WithTraceID("trace-abc123")
+
+	data, err := xml.Marshal(original)
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+
+	var decoded RFC9457Error
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, original) {
+		t.Errorf("Round trip = %+v, want %+v", decoded, *original)
+	}
+}
+
+func TestRFC9457Error_XMLRoundTrip_Nil(t *testing.T) {
+	data, err := xml.Marshal(NewRFC9457Error(400, "Bad Request", "detail").WithExtension("empty", nil))
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+
+	var decoded RFC9457Error
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	if v, ok := decoded.Extensions["empty"]; !ok || v != "" {
+		t.Errorf("Extensions[\"empty\"] = %#v, want \"\"", v)
+	}
+}
+
+func TestRFC7807Error_XML(t *testing.T) {
+	original := ForbiddenProblem7807("Access denied").WithExtension("required_role", "admin")
+
+	data, err := xml.Marshal(original)
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+	if !strings.HasPrefix(string(data), `<problem xmlns="urn:ietf:rfc:7807">`) {
+		t.Errorf("Expected problem root element, got %s", data)
+	}
+
+	var decoded RFC7807Error
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, original) {
+		t.Errorf("Round trip = %+v, want %+v", decoded, *original)
+	}
+}
+
+func TestToXMLHttpError(t *testing.T) {
+	he := BadRequestProblem9457("Invalid input").ToXMLHttpError()
+
+	if he.Code != 400 {
+		t.Errorf("Code = %d, want 400", he.Code)
+	}
+	if he.ContentType != "application/problem+xml" {
+		t.Errorf("ContentType = %s, want application/problem+xml", he.ContentType)
+	}
+	if !strings.HasPrefix(he.Message, xml.Header) {
+		t.Errorf("Expected XML declaration, got %s", he.Message)
+	}
+
+	he7807 := BadRequestProblem7807("Invalid input").ToXMLHttpError()
+	if he7807.ContentType != "application/problem+xml" {
+		t.Errorf("ContentType = %s, want application/problem+xml", he7807.ContentType)
+	}
+}
@@ -0,0 +1,197 @@
+package httperror
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ProblemType describes a registered problem type as defined in RFC9457 Section 3.1.1.
+type ProblemType struct {
+	// URI is the type URI that identifies the problem type.
+	URI string `json:"type"`
+
+	// Title is the canonical, human-readable summary of the problem type.
+	// RFC9457 requires it to stay the same for every occurrence of the problem, except for localization.
+	Title string `json:"title"`
+
+	// Status is the default HTTP status code for occurrences of the problem type.
+	Status int `json:"status"`
+
+	// Description is human-readable documentation explaining the problem type and how to resolve it.
+	Description string `json:"description,omitempty"`
+}
+
+// Registry holds the problem types known to an application, keyed by type URI.
+// It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]ProblemType
+}
+
+// NewRegistry creates a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		types: make(map[string]ProblemType),
+	}
+}
+
+// DefaultRegistry is the registry pre-populated with CommonProblemTypes.
+// Applications can register their own problem types in it or create a separate Registry.
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.ValidationError,
+		Title:       "Bad Request",
+		Status:      http.StatusBadRequest,
+		Description: "The request could not be processed because its input failed validation.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.UnprocessableEntity,
+		Title:       "Unprocessable Entity",
+		Status:      http.StatusUnprocessableEntity,
+		Description: "The request was well-formed, but its content failed validation.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.AuthenticationRequired,
+		Title:       "Unauthorized",
+		Status:      http.StatusUnauthorized,
+		Description: "The request requires valid authentication credentials.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.AuthorizationFailed,
+		Title:       "Forbidden",
+		Status:      http.StatusForbidden,
+		Description: "The authenticated client is not allowed to perform the requested operation.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.ResourceNotFound,
+		Title:       "Not Found",
+		Status:      http.StatusNotFound,
+		Description: "The requested resource does not exist.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.ResourceConflict,
+		Title:       "Conflict",
+		Status:      http.StatusConflict,
+		Description: "The request conflicts with the current state of the resource.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.RateLimitExceeded,
+		Title:       "Too Many Requests",
+		Status:      http.StatusTooManyRequests,
+		Description: "The client has sent too many requests in a given amount of time. Retry after the indicated delay.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.ServiceUnavailable,
+		Title:       "Service Unavailable",
+		Status:      http.StatusServiceUnavailable,
+		Description: "The service is temporarily unable to handle the request.",
+	})
+	r.MustRegister(ProblemType{
+		URI:         CommonProblemTypes.InternalError,
+		Title:       "Internal Server Error",
+		Status:      http.StatusInternalServerError,
+		Description: "The server encountered an unexpected condition that prevented it from fulfilling the request.",
+	})
+	return r
+}
+
+// Register adds a problem type to the registry.
+// It returns an error if the type URI, title or status is invalid, or if the type URI
+// is already registered with a different title or status, since RFC9457 requires
+// the title of a problem type to stay consistent.
+// Registering an identical problem type again is a no-op apart from updating its description.
+func (r *Registry) Register(pt ProblemType) error {
+	if pt.URI == "" || pt.URI == "about:blank" {
+		return fmt.Errorf("problem type URI %q cannot be registered", pt.URI)
+	}
+	if pt.Title == "" {
+		return fmt.Errorf("problem type %q: title is required", pt.URI)
+	}
+	if pt.Status < 100 || pt.Status >= 600 {
+		return fmt.Errorf("problem type %q: status must be a valid HTTP status code (100-599)", pt.URI)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.types[pt.URI]; ok {
+		if existing.Title != pt.Title {
+			return fmt.Errorf("problem type %q is already registered with title %q", pt.URI, existing.Title)
+		}
+		if existing.Status != pt.Status {
+			return fmt.Errorf("problem type %q is already registered with status %d", pt.URI, existing.Status)
+		}
+	}
+	r.types[pt.URI] = pt
+	return nil
+}
+
+// MustRegister is like Register but panics if the problem type cannot be registered.
+// It is intended for registering problem types during package initialization.
+func (r *Registry) MustRegister(pt ProblemType) {
+	if err := r.Register(pt); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the problem type registered for the given type URI.
+func (r *Registry) Lookup(typeURI string) (ProblemType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pt, ok := r.types[typeURI]
+	return pt, ok
+}
+
+// LookupStatus returns the problem type registered with the given default status code.
+// If several types share the status code, the first one in type URI order is returned.
+func (r *Registry) LookupStatus(status int) (ProblemType, bool) {
+	for _, pt := range r.Types() {
+		if pt.Status == status {
+			return pt, true
+		}
+	}
+	return ProblemType{}, false
+}
+
+// Types returns all registered problem types, sorted by type URI.
+func (r *Registry) Types() []ProblemType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]ProblemType, 0, len(r.types))
+	for _, pt := range r.types {
+		types = append(types, pt)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].URI < types[j].URI
+	})
+	return types
+}
+
+// New creates a new RFC9457Error for a registered problem type, filling in its canonical title and default status.
+// If typeURI is not registered, a 500 Internal Server Error problem with the given type URI is returned,
+// so that a missing registration surfaces as a server error rather than a misleading client error.
+func (r *Registry) New(typeURI, detail string) *RFC9457Error {
+	pt, ok := r.Lookup(typeURI)
+	if !ok {
+		return NewRFC9457ErrorWithType(http.StatusInternalServerError, typeURI, http.StatusText(http.StatusInternalServerError), detail)
+	}
+	return NewRFC9457ErrorWithType(pt.Status, pt.URI, pt.Title, detail)
+}
+
+// Validate checks that a problem using a registered type carries the canonical title of that type,
+// as required by RFC9457. Problems with unregistered types are not checked.
+func (r *Registry) Validate(p *RFC9457Error) error {
+	pt, ok := r.Lookup(p.Type)
+	if !ok {
+		return nil
+	}
+	if p.Title != pt.Title {
+		return fmt.Errorf("problem type %q must have title %q, got %q", pt.URI, pt.Title, p.Title)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package httperror
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	outOfCredit := ProblemType{
+		URI:         "https://example.com/probs/out-of-credit",
+		Title:       "You do not have enough credit.",
+		Status:      http.StatusForbidden,
+		Description: "The account balance is too low for the requested operation.",
+	}
+
+	tests := []struct {
+		name    string
+		pt      ProblemType
+		wantErr bool
+	}{
+		{"Valid type", outOfCredit, false},
+		{"Same type again", outOfCredit, false},
+		{"Different title", ProblemType{URI: outOfCredit.URI, Title: "No credit", Status: http.StatusForbidden}, true},
+		{"Different status", ProblemType{URI: outOfCredit.URI, Title: outOfCredit.Title, Status: http.StatusPaymentRequired}, true},
+		{"Empty URI", ProblemType{Title: "Empty", Status: http.StatusBadRequest}, true},
+		{"about:blank", ProblemType{URI: "about:blank", Title: "Blank", Status: http.StatusBadRequest}, true},
+		{"Empty title", ProblemType{URI: "https://example.com/probs/untitled", Status: http.StatusBadRequest}, true},
+		{"Invalid status", ProblemType{URI: "https://example.com/probs/invalid", Title: "Invalid", Status: 42}, true},
+	}
+
+	r := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Register(tt.pt)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := len(r.Types()); got != 1 {
+		t.Errorf("len(Types()) = %d, want 1", got)
+	}
+}
+
+func TestRegistry_New(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(ProblemType{
+		URI:    "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: http.StatusForbidden,
+	})
+
+	p := r.New("https://example.com/probs/out-of-credit", "Your current balance is 30, but that costs 50.")
+	if p.Status != http.StatusForbidden {
+		t.Errorf("Status = %d, want %d", p.Status, http.StatusForbidden)
+	}
+	if p.Title != "You do not have enough credit." {
+		t.Errorf("Title = %s, want %s", p.Title, "You do not have enough credit.")
+	}
+	if p.Detail != "Your current balance is 30, but that costs 50." {
+		t.Errorf("Detail = %s", p.Detail)
+	}
+	if err := r.Validate(p); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	unknown := r.New("https://example.com/probs/unknown", "detail")
+	if unknown.Status != http.StatusInternalServerError {
+		t.Errorf("Status for unregistered type = %d, want %d", unknown.Status, http.StatusInternalServerError)
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(ProblemType{
+		URI:    "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: http.StatusForbidden,
+	})
+
+	inconsistent := NewRFC9457ErrorWithType(http.StatusForbidden, "https://example.com/probs/out-of-credit", "Out of credit", "detail")
+	if err := r.Validate(inconsistent); err == nil {
+		t.Error("Validate() should reject a title that differs from the registered one")
+	}
+
+	unregistered := NewRFC9457ErrorWithType(http.StatusForbidden, "https://example.com/probs/other", "Anything", "detail")
+	if err := r.Validate(unregistered); err != nil {
+		t.Errorf("Validate() error = %v for unregistered type", err)
+	}
+}
+
+func TestDefaultRegistry_BuiltInConstructors(t *testing.T) {
+	constructors := map[string]func(string, ...string) *RFC9457Error{
+		"BadRequestProblem9457":                    BadRequestProblem9457,
+		"UnauthorizedProblem9457":                  UnauthorizedProblem9457,
+		"PaymentRequiredProblem9457":               PaymentRequiredProblem9457,
+		"ForbiddenProblem9457":                     ForbiddenProblem9457,
+		"NotFoundProblem9457":                      NotFoundProblem9457,
+		"MethodNotAllowedProblem9457":              MethodNotAllowedProblem9457,
+		"NotAcceptableProblem9457":                 NotAcceptableProblem9457,
+		"ProxyAuthRequiredProblem9457":             ProxyAuthRequiredProblem9457,
+		"RequestTimeoutProblem9457":                RequestTimeoutProblem9457,
+		"ConflictProblem9457":                      ConflictProblem9457,
+		"GoneProblem9457":                          GoneProblem9457,
+		"LengthRequiredProblem9457":                LengthRequiredProblem9457,
+		"PreconditionFailedProblem9457":            PreconditionFailedProblem9457,
+		"PayloadTooLargeProblem9457":               PayloadTooLargeProblem9457,
+		"URITooLongProblem9457":                    URITooLongProblem9457,
+		"UnsupportedMediaTypeProblem9457":          UnsupportedMediaTypeProblem9457,
+		"RangeNotSatisfiableProblem9457":           RangeNotSatisfiableProblem9457,
+		"ExpectationFailedProblem9457":             ExpectationFailedProblem9457,
+		"MisdirectedRequestProblem9457":            MisdirectedRequestProblem9457,
+		"UnprocessableEntityProblem9457":           UnprocessableEntityProblem9457,
+		"LockedProblem9457":                        LockedProblem9457,
+		"FailedDependencyProblem9457":              FailedDependencyProblem9457,
+		"UpgradeRequiredProblem9457":               UpgradeRequiredProblem9457,
+		"PreconditionRequiredProblem9457":          PreconditionRequiredProblem9457,
+		"TooManyRequestsProblem9457":               TooManyRequestsProblem9457,
+		"RequestHeaderFieldsTooLargeProblem9457":   RequestHeaderFieldsTooLargeProblem9457,
+		"UnavailableForLegalReasonsProblem9457":    UnavailableForLegalReasonsProblem9457,
+		"InternalServerErrorProblem9457":           InternalServerErrorProblem9457,
+		"NotImplementedProblem9457":                NotImplementedProblem9457,
+		"BadGatewayProblem9457":                    BadGatewayProblem9457,
+		"ServiceUnavailableProblem9457":            ServiceUnavailableProblem9457,
+		"GatewayTimeoutProblem9457":                GatewayTimeoutProblem9457,
+		"HTTPVersionNotSupportedProblem9457":       HTTPVersionNotSupportedProblem9457,
+		"VariantAlsoNegotiatesProblem9457":         VariantAlsoNegotiatesProblem9457,
+		"InsufficientStorageProblem9457":           InsufficientStorageProblem9457,
+		"LoopDetectedProblem9457":                  LoopDetectedProblem9457,
+		"NotExtendedProblem9457":                   NotExtendedProblem9457,
+		"NetworkAuthenticationRequiredProblem9457": NetworkAuthenticationRequiredProblem9457,
+		"BadRequestProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return BadRequestProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"UnauthorizedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return UnauthorizedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"PaymentRequiredProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return PaymentRequiredProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"ForbiddenProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return ForbiddenProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"NotFoundProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return NotFoundProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"MethodNotAllowedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return MethodNotAllowedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"NotAcceptableProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return NotAcceptableProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"ProxyAuthRequiredProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return ProxyAuthRequiredProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"RequestTimeoutProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return RequestTimeoutProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"ConflictProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return ConflictProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"GoneProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return GoneProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"LengthRequiredProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return LengthRequiredProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"PreconditionFailedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return PreconditionFailedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"PayloadTooLargeProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return PayloadTooLargeProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"URITooLongProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return URITooLongProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"UnsupportedMediaTypeProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return UnsupportedMediaTypeProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"RangeNotSatisfiableProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return RangeNotSatisfiableProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"ExpectationFailedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return ExpectationFailedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"MisdirectedRequestProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return MisdirectedRequestProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"LockedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return LockedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"FailedDependencyProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return FailedDependencyProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"UpgradeRequiredProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return UpgradeRequiredProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"PreconditionRequiredProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return PreconditionRequiredProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"TooManyRequestsProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return TooManyRequestsProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"RequestHeaderFieldsTooLargeProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return RequestHeaderFieldsTooLargeProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"UnavailableForLegalReasonsProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return UnavailableForLegalReasonsProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"NotImplementedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return NotImplementedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"BadGatewayProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return BadGatewayProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"GatewayTimeoutProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return GatewayTimeoutProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"HTTPVersionNotSupportedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return HTTPVersionNotSupportedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"VariantAlsoNegotiatesProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return VariantAlsoNegotiatesProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"InsufficientStorageProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return InsufficientStorageProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"LoopDetectedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return LoopDetectedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"NotExtendedProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return NotExtendedProblem7807(detail, title...).ToRFC9457Error()
+		},
+		"NetworkAuthenticationRequiredProblem7807": func(detail string, title ...string) *RFC9457Error {
+			return NetworkAuthenticationRequiredProblem7807(detail, title...).ToRFC9457Error()
+		},
+	}
+
+	for name, constructor := range constructors {
+		for _, p := range []*RFC9457Error{constructor("detail"), constructor("detail", "Custom Title")} {
+			if err := DefaultRegistry.Validate(p); err != nil {
+				t.Errorf("%s: DefaultRegistry.Validate() error = %v", name, err)
+			}
+			if err := p.Validate(); err != nil {
+				t.Errorf("%s: Validate() error = %v", name, err)
+			}
+		}
+	}
+
+	inconsistent := NewRFC9457ErrorWithType(http.StatusNotFound, CommonProblemTypes.ResourceNotFound, "Missing", "detail")
+	if err := inconsistent.Validate(); err == nil {
+		t.Error("Validate() should reject a registered type with a title other than the registered one")
+	}
+}
+
+func TestRegistry_MustRegisterPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustRegister() should panic for an invalid problem type")
+		}
+	}()
+	NewRegistry().MustRegister(ProblemType{})
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	for _, uri := range []string{
+		CommonProblemTypes.ValidationError,
+		CommonProblemTypes.UnprocessableEntity,
+		CommonProblemTypes.AuthenticationRequired,
+		CommonProblemTypes.AuthorizationFailed,
+		CommonProblemTypes.ResourceNotFound,
+		CommonProblemTypes.ResourceConflict,
+		CommonProblemTypes.RateLimitExceeded,
+		CommonProblemTypes.ServiceUnavailable,
+		CommonProblemTypes.InternalError,
+	} {
+		if _, ok := DefaultRegistry.Lookup(uri); !ok {
+			t.Errorf("DefaultRegistry is missing %s", uri)
+		}
+	}
+
+	if pt, ok := DefaultRegistry.LookupStatus(http.StatusNotFound); !ok || pt.URI != CommonProblemTypes.ResourceNotFound {
+		t.Errorf("LookupStatus(404) = %v, %v, want %s", pt, ok, CommonProblemTypes.ResourceNotFound)
+	}
+	if _, ok := DefaultRegistry.LookupStatus(http.StatusTeapot); ok {
+		t.Error("LookupStatus(418) should not find a problem type")
+	}
+
+	p := DefaultRegistry.New(CommonProblemTypes.ResourceNotFound, "User 123 not found")
+	if p.Status != http.StatusNotFound || p.Title != "Not Found" {
+		t.Errorf("New() = %d %q, want 404 %q", p.Status, p.Title, "Not Found")
+	}
+}
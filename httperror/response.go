@@ -0,0 +1,71 @@
+package httperror
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxResponseBodySize is the maximum number of bytes FromResponse reads from a response body
+// when no explicit limit is given.
+const DefaultMaxResponseBodySize int64 = 1 << 20 // 1 MiB
+
+// FromResponse converts a non-2xx *http.Response into an error, or returns nil for 2xx responses.
+// An application/problem+json or application/problem+xml body is decoded into an *RFC9457Error, keeping its type URI and extension
+// members; if the document has no valid status member, the response status code is used.
+// Any other body is returned as an *HttpError carrying the response status code and the body as its message.
+//
+// At most maxBodySize bytes are read from the body (DefaultMaxResponseBodySize if omitted).
+// The body is consumed but not closed; closing it remains the caller's responsibility.
+func FromResponse(resp *http.Response, maxBodySize ...int64) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	limit := DefaultMaxResponseBodySize
+	if len(maxBodySize) > 0 && maxBodySize[0] > 0 {
+		limit = maxBodySize[0]
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(resp.Body, limit))
+		if err != nil {
+			return New(resp.StatusCode, http.StatusText(resp.StatusCode)).WithCause(err)
+		}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	var unmarshal func([]byte, interface{}) error
+	switch mediaType {
+	case "application/problem+json":
+		unmarshal = json.Unmarshal
+	case "application/problem+xml":
+		unmarshal = xml.Unmarshal
+	}
+	if unmarshal != nil {
+		problem := &RFC9457Error{}
+		if err := unmarshal(body, problem); err == nil {
+			if problem.Status == 0 {
+				problem.Status = resp.StatusCode
+			}
+			return problem
+		}
+	}
+
+	// Plain text bodies written by http.Error end with a newline
+	message := strings.TrimSuffix(string(body), "\n")
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	if mediaType == "" || mediaType == "text/plain" {
+		return New(resp.StatusCode, message)
+	}
+	return New(resp.StatusCode, message, contentType)
+}
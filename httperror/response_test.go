@@ -0,0 +1,147 @@
+package httperror
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newResponse(status int, contentType, body string) *http.Response {
+	header := make(http.Header)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestFromResponse_Success(t *testing.T) {
+	for _, status := range []int{200, 201, 204} {
+		if err := FromResponse(newResponse(status, "application/json", `{}`)); err != nil {
+			t.Errorf("FromResponse() with status %d = %v, want nil", status, err)
+		}
+	}
+}
+
+func TestFromResponse_Problem(t *testing.T) {
+	resp := newResponse(403, "application/problem+json; charset=utf-8",
+		`{"type":"https://example.com/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","balance":30}`)
+
+	err := FromResponse(resp)
+
+	var problem *RFC9457Error
+	if !errors.As(err, &problem) {
+		t.Fatalf("FromResponse() = %T, want *RFC9457Error", err)
+	}
+	if problem.Type != "https://example.com/out-of-credit" {
+		t.Errorf("Type = %s, want https://example.com/out-of-credit", problem.Type)
+	}
+	if problem.Status != 403 {
+		t.Errorf("Status = %d, want 403", problem.Status)
+	}
+	if problem.Extensions["balance"] != float64(30) {
+		t.Errorf("balance = %v, want 30", problem.Extensions["balance"])
+	}
+}
+
+func TestFromResponse_ProblemWithoutStatus(t *testing.T) {
+	err := FromResponse(newResponse(409, "application/problem+json", `{"title":"Conflict","status":"409"}`))
+
+	var problem *RFC9457Error
+	if !errors.As(err, &problem) {
+		t.Fatalf("FromResponse() = %T, want *RFC9457Error", err)
+	}
+	if problem.Status != 409 {
+		t.Errorf("Status = %d, want 409", problem.Status)
+	}
+}
+
+func TestFromResponse_HttpError(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		contentType     string
+		body            string
+		wantMessage     string
+		wantContentType string
+	}{
+		{
+			name:        "Plain text from http.Error",
+			status:      400,
+			contentType: "text/plain; charset=utf-8",
+			body:        "name is required\n",
+			wantMessage: "name is required",
+		},
+		{
+			name:        "Empty body",
+			status:      502,
+			wantMessage: "Bad Gateway",
+		},
+		{
+			name:            "Custom content type",
+			status:          400,
+			contentType:     "application/json",
+			body:            `{"error":"bad request"}`,
+			wantMessage:     `{"error":"bad request"}`,
+			wantContentType: "application/json",
+		},
+		{
+			name:            "Malformed problem document",
+			status:          500,
+			contentType:     "application/problem+json",
+			body:            `{"title":`,
+			wantMessage:     `{"title":`,
+			wantContentType: "application/problem+json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromResponse(newResponse(tt.status, tt.contentType, tt.body))
+
+			var he *HttpError
+			if !errors.As(err, &he) {
+				t.Fatalf("FromResponse() = %T, want *HttpError", err)
+			}
+			if he.Code != tt.status {
+				t.Errorf("Code = %d, want %d", he.Code, tt.status)
+			}
+			if he.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", he.Message, tt.wantMessage)
+			}
+			if he.ContentType != tt.wantContentType {
+				t.Errorf("ContentType = %q, want %q", he.ContentType, tt.wantContentType)
+			}
+		})
+	}
+}
+
+func TestFromResponse_BodyLimit(t *testing.T) {
+	err := FromResponse(newResponse(500, "text/plain", strings.Repeat("a", 100)), 10)
+
+	var he *HttpError
+	if !errors.As(err, &he) {
+		t.Fatalf("FromResponse() = %T, want *HttpError", err)
+	}
+	if len(he.Message) != 10 {
+		t.Errorf("len(Message) = %d, want 10", len(he.Message))
+	}
+}
+
+func TestFromResponse_ProblemXML(t *testing.T) {
+	body := NotFoundProblem9457("User 123 not found").WithInstance("/api/users/123").ToXMLHttpError().Message
+	err := FromResponse(newResponse(404, "application/problem+xml", body))
+
+	var problem *RFC9457Error
+	if !errors.As(err, &problem) {
+		t.Fatalf("FromResponse() = %T, want *RFC9457Error", err)
+	}
+	if problem.Status != 404 || problem.Instance != "/api/users/123" {
+		t.Errorf("Unexpected problem %+v", problem)
+	}
+}
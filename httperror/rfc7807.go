@@ -2,6 +2,7 @@ package httperror
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 )
@@ -42,6 +43,14 @@ type RFC7807Error struct {
 	// Extensions is a map of additional members that provide information about the problem.
 	// These can be application-specific or extended members from other specifications.
 	Extensions map[string]interface{} `json:"-"`
+
+	// cause is the underlying error that caused this problem.
+	// It is kept for logging and error matching and is never serialized.
+	cause error
+
+	// messageKey and messageArgs identify the localized detail of this problem in a Catalog.
+	messageKey  string
+	messageArgs []interface{}
 }
 
 // NewRFC7807Error creates a new RFC7807Error with the specified status, title, and detail.
@@ -83,11 +92,42 @@ func (p *RFC7807Error) WithExtension(key string, value interface{}) *RFC7807Erro
 	return p
 }
 
+// WithCause sets the underlying error that caused this problem and returns the error for method chaining.
+// The cause is available through errors.Is and errors.As and is included in Error(),
+// but it is never serialized into the problem document.
+func (p *RFC7807Error) WithCause(cause error) *RFC7807Error {
+	p.cause = cause
+	return p
+}
+
+// WithMessageKey sets the Catalog key and placeholder arguments of the localized detail and returns the error
+// for method chaining. Renderers configured with a Catalog replace Detail with the message for the key in the
+// language negotiated from the request's Accept-Language header; Detail is used when no translation exists.
+func (p *RFC7807Error) WithMessageKey(key string, args ...interface{}) *RFC7807Error {
+	p.messageKey = key
+	p.messageArgs = args
+	return p
+}
+
+// MessageKey returns the Catalog key and placeholder arguments of the localized detail, implementing the Localizable interface.
+func (p *RFC7807Error) MessageKey() (key string, args []interface{}) {
+	return p.messageKey, p.messageArgs
+}
+
 // Error returns a string representation of the problem detail, implementing the error interface.
+// If a cause is set, it is appended after the detail.
 func (p *RFC7807Error) Error() string {
+	if p.cause != nil {
+		return fmt.Sprintf("%d: %s - %s: %v", p.Status, p.Title, p.Detail, p.cause)
+	}
 	return fmt.Sprintf("%d: %s - %s", p.Status, p.Title, p.Detail)
 }
 
+// Unwrap returns the underlying cause of the problem, if any.
+func (p *RFC7807Error) Unwrap() error {
+	return p.cause
+}
+
 // StatusCode returns the HTTP status code of the problem detail.
 func (p *RFC7807Error) StatusCode() int {
 	return p.Status
@@ -98,6 +138,22 @@ func (p *RFC7807Error) ErrorMessage() string {
 	return p.Detail
 }
 
+// ProblemDetails returns the problem as an RFC9457Error. This implements the ProblemDetailer interface.
+func (p *RFC7807Error) ProblemDetails() *RFC9457Error {
+	return p.ToRFC9457Error()
+}
+
+// RenderProblem renders the problem detail as an application/problem+json document, regardless of the request.
+// The wrappers' renderer uses ProblemDetails instead, so that the format is negotiated from the Accept header
+// of each request and the response carries Vary: Accept.
+func (p *RFC7807Error) RenderProblem() (string, []byte, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return "", nil, err
+	}
+	return "application/problem+json", body, nil
+}
+
 // MarshalJSON implements the json.Marshaler interface to include extensions in the JSON output.
 func (p *RFC7807Error) MarshalJSON() ([]byte, error) {
 	type Alias RFC7807Error
@@ -129,6 +185,54 @@ func (p *RFC7807Error) MarshalJSON() ([]byte, error) {
 	return json.Marshal(result)
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface, the counterpart of MarshalJSON.
+// Unknown top-level members are collected into Extensions. Standard members with the wrong
+// JSON type are ignored rather than failing the decode, as required by RFC9457 Section 3.1.
+func (p *RFC7807Error) UnmarshalJSON(data []byte) error {
+	fields, extensions, err := unmarshalProblem(data)
+	if err != nil {
+		return err
+	}
+	p.setMembers(fields, extensions)
+	return nil
+}
+
+// MarshalXML implements the xml.Marshaler interface, encoding the problem as an
+// application/problem+xml document in the urn:ietf:rfc:7807 namespace as defined in RFC9457 Appendix B.
+// Extension members are encoded as child elements, with arrays represented as <i> elements and nil values
+// as empty elements. It fails for extension members whose names are not valid XML names.
+func (p *RFC7807Error) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalProblemXML(e, p.Type, p.Title, p.Status, p.Detail, p.Instance, p.Extensions)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface, the counterpart of MarshalXML.
+// Unknown child elements are collected into Extensions. Since XML carries no type information,
+// extension values are decoded as strings, arrays or objects; nil values encoded by MarshalXML are decoded as "".
+func (p *RFC7807Error) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	fields, extensions, err := unmarshalProblemXML(d, start)
+	if err != nil {
+		return err
+	}
+	p.setMembers(fields, extensions)
+	return nil
+}
+
+// setMembers stores decoded standard and extension members in the problem.
+func (p *RFC7807Error) setMembers(fields problemFields, extensions map[string]interface{}) {
+	assign(&p.Type, fields.Type)
+	assign(&p.Title, fields.Title)
+	assign(&p.Status, fields.Status)
+	assign(&p.Detail, fields.Detail)
+	assign(&p.Instance, fields.Instance)
+
+	for k, v := range extensions {
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions[k] = v
+	}
+}
+
 // BadRequestProblem7807 creates a new RFC7807Error with status 400 (Bad Request).
 // If title is empty, it defaults to "Bad Request".
 func BadRequestProblem7807(detail string, title ...string) *RFC7807Error {
@@ -486,7 +590,48 @@ func (p *RFC7807Error) ToHttpError() *HttpError {
 	jsonBytes, err := json.Marshal(p)
 	if err != nil {
 		// If marshaling fails, fall back to just using the detail
-		return New(p.Status, p.Detail, "text/plain")
+		return New(p.Status, p.Detail, "text/plain").WithCause(p.cause).WithMessageKey(p.messageKey, p.messageArgs...)
+	}
+	he := New(p.Status, string(jsonBytes), ContentType).WithCause(p.cause).WithMessageKey(p.messageKey, p.messageArgs...)
+	he.problem = p.ToRFC9457Error()
+	return he
+}
+
+// ToXMLHttpError converts the RFC7807Error to an HttpError with the XML representation of the problem.
+// The resulting HttpError will have the content type set to "application/problem+xml".
+// Problems that have no XML representation, such as those with extension members whose names are
+// not valid XML names, are converted with ToHttpError instead, keeping every member.
+func (p *RFC7807Error) ToXMLHttpError() *HttpError {
+	const contentType = "application/problem+xml"
+	xmlBytes, err := xml.Marshal(p)
+	if err != nil {
+		return p.ToHttpError()
 	}
-	return New(p.Status, string(jsonBytes), ContentType)
+	he := New(p.Status, xml.Header+string(xmlBytes), contentType).WithCause(p.cause).WithMessageKey(p.messageKey, p.messageArgs...)
+	he.problem = p.ToRFC9457Error()
+	return he
+}
+
+// ToRFC9457Error converts a RFC7807Error to a RFC9457Error.
+// RFC9457 obsoletes RFC7807 with a compatible format, so all members and the cause are preserved.
+func (p *RFC7807Error) ToRFC9457Error() *RFC9457Error {
+	rfc9457 := &RFC9457Error{
+		Type:       p.Type,
+		Title:      p.Title,
+		Status:     p.Status,
+		Detail:     p.Detail,
+		Instance:   p.Instance,
+		Extensions: make(map[string]interface{}),
+		cause:      p.cause,
+
+		messageKey:  p.messageKey,
+		messageArgs: p.messageArgs,
+	}
+
+	// Copy extensions
+	for k, v := range p.Extensions {
+		rfc9457.Extensions[k] = v
+	}
+
+	return rfc9457
 }
@@ -2,8 +2,10 @@ package httperror
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -175,6 +177,40 @@ func TestRFC7807Error_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestRFC7807Error_UnmarshalJSON(t *testing.T) {
+	original := NewRFC7807Error(403, "Forbidden", "Insufficient permissions").
+		WithType("https://example.com/errors/forbidden").
+		WithInstance("/api/resources/123").
+		WithExtension("required_role", "admin")
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var decoded RFC7807Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if decoded.Type != original.Type || decoded.Status != original.Status || decoded.Instance != original.Instance {
+		t.Errorf("Expected %+v, got %+v", *original, decoded)
+	}
+
+	if decoded.Extensions["required_role"] != "admin" {
+		t.Errorf("Expected required_role extension to be admin, got %v", decoded.Extensions["required_role"])
+	}
+
+	// Standard members with the wrong type are ignored
+	var lenient RFC7807Error
+	if err := json.Unmarshal([]byte(`{"status":"403","title":"Forbidden"}`), &lenient); err != nil {
+		t.Fatalf("Expected wrong member types to be ignored, got %v", err)
+	}
+	if lenient.Status != 0 || lenient.Title != "Forbidden" {
+		t.Errorf("Expected status to be ignored and title kept, got %+v", lenient)
+	}
+}
+
 func TestRFC7807Error_StatusCode(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -217,6 +253,49 @@ func TestRFC7807Error_ErrorMessage(t *testing.T) {
 	}
 }
 
+func TestRFC7807Error_RenderProblem(t *testing.T) {
+	var renderer ProblemRenderer = NewRFC7807Error(403, "Forbidden", "Access denied")
+
+	contentType, body, err := renderer.RenderProblem()
+	if err != nil {
+		t.Fatalf("RenderProblem() error = %v", err)
+	}
+
+	if contentType != "application/problem+json" {
+		t.Errorf("Expected content type application/problem+json, got %s", contentType)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("Failed to unmarshal body: %v", err)
+	}
+
+	if result["status"] != float64(403) {
+		t.Errorf("Expected status 403, got %v", result["status"])
+	}
+}
+
+func TestRFC7807Error_WithCause(t *testing.T) {
+	cause := errors.New("connection refused")
+	problem := BadGatewayProblem7807("Upstream unavailable").WithCause(cause)
+
+	if !errors.Is(problem, cause) {
+		t.Error("Expected errors.Is to find the cause")
+	}
+
+	if !errors.Is(problem.ToHttpError(), cause) {
+		t.Error("Expected ToHttpError to preserve the cause")
+	}
+
+	data, err := json.Marshal(problem)
+	if err != nil {
+		t.Fatalf("Failed to marshal problem: %v", err)
+	}
+	if strings.Contains(string(data), "connection refused") {
+		t.Errorf("Expected cause to be omitted from JSON, got %s", data)
+	}
+}
+
 func TestRFC7807Error_ToHttpError(t *testing.T) {
 	// Create a problem detail
 	problem := NewRFC7807Error(403, "Forbidden", "Access denied")
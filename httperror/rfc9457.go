@@ -2,6 +2,7 @@ package httperror
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"strings"
@@ -55,13 +56,24 @@ type RFC9457Error struct {
 	// These can be application-specific or extended members from other specifications.
 	// RFC9457 allows for extension members with improved guidance on their usage.
 	Extensions map[string]interface{} `json:"-"`
+
+	// cause is the underlying error that caused this problem.
+	// It is kept for logging and error matching and is never serialized.
+	cause error
+
+	// messageKey and messageArgs identify the localized detail of this problem in a Catalog.
+	messageKey  string
+	messageArgs []interface{}
 }
 
 // CommonProblemTypes defines a registry of common problem type URIs as suggested in RFC9457 Section 4.2.
 // These provide standardized problem types that can be reused across applications.
+// They are registered in DefaultRegistry with their canonical titles and default status codes.
 var CommonProblemTypes = struct {
 	// ValidationError represents a problem where input validation failed
 	ValidationError string
+	// UnprocessableEntity represents a problem where the request was well-formed but its content could not be processed
+	UnprocessableEntity string
 	// AuthenticationRequired represents a problem where authentication is required
 	AuthenticationRequired string
 	// AuthorizationFailed represents a problem where authorization failed
@@ -78,6 +90,7 @@ var CommonProblemTypes = struct {
 	InternalError string
 }{
 	ValidationError:        "https://httpstatuses.io/400",
+	UnprocessableEntity:    "https://httpstatuses.io/422",
 	AuthenticationRequired: "https://httpstatuses.io/401",
 	AuthorizationFailed:    "https://httpstatuses.io/403",
 	ResourceNotFound:       "https://httpstatuses.io/404",
@@ -174,11 +187,42 @@ func (p *RFC9457Error) WithRetryAfter(seconds int) *RFC9457Error {
 	return p.WithExtension("retry-after", seconds)
 }
 
+// WithCause sets the underlying error that caused this problem and returns the error for method chaining.
+// The cause is available through errors.Is and errors.As and is included in Error(),
+// but it is never serialized into the problem document.
+func (p *RFC9457Error) WithCause(cause error) *RFC9457Error {
+	p.cause = cause
+	return p
+}
+
+// WithMessageKey sets the Catalog key and placeholder arguments of the localized detail and returns the error
+// for method chaining. Renderers configured with a Catalog replace Detail with the message for the key in the
+// language negotiated from the request's Accept-Language header; Detail is used when no translation exists.
+func (p *RFC9457Error) WithMessageKey(key string, args ...interface{}) *RFC9457Error {
+	p.messageKey = key
+	p.messageArgs = args
+	return p
+}
+
+// MessageKey returns the Catalog key and placeholder arguments of the localized detail, implementing the Localizable interface.
+func (p *RFC9457Error) MessageKey() (key string, args []interface{}) {
+	return p.messageKey, p.messageArgs
+}
+
 // Error returns a string representation of the problem detail, implementing the error interface.
+// If a cause is set, it is appended after the detail.
 func (p *RFC9457Error) Error() string {
+	if p.cause != nil {
+		return fmt.Sprintf("%d: %s - %s: %v", p.Status, p.Title, p.Detail, p.cause)
+	}
 	return fmt.Sprintf("%d: %s - %s", p.Status, p.Title, p.Detail)
 }
 
+// Unwrap returns the underlying cause of the problem, if any.
+func (p *RFC9457Error) Unwrap() error {
+	return p.cause
+}
+
 // StatusCode returns the HTTP status code of the problem detail.
 func (p *RFC9457Error) StatusCode() int {
 	return p.Status
@@ -189,25 +233,27 @@ func (p *RFC9457Error) ErrorMessage() string {
 	return p.Detail
 }
 
-// IsCommonType checks if the error uses one of the predefined common problem types.
-func (p *RFC9457Error) IsCommonType() bool {
-	commonTypes := []string{
-		CommonProblemTypes.ValidationError,
-		CommonProblemTypes.AuthenticationRequired,
-		CommonProblemTypes.AuthorizationFailed,
-		CommonProblemTypes.ResourceNotFound,
-		CommonProblemTypes.ResourceConflict,
-		CommonProblemTypes.RateLimitExceeded,
-		CommonProblemTypes.ServiceUnavailable,
-		CommonProblemTypes.InternalError,
-	}
-
-	for _, commonType := range commonTypes {
-		if p.Type == commonType {
-			return true
-		}
+// ProblemDetails returns the problem itself. This implements the ProblemDetailer interface.
+func (p *RFC9457Error) ProblemDetails() *RFC9457Error {
+	return p
+}
+
+// RenderProblem renders the problem detail as an application/problem+json document, regardless of the request.
+// The wrappers' renderer uses ProblemDetails instead, so that the format is negotiated from the Accept header
+// of each request and the response carries Vary: Accept.
+func (p *RFC9457Error) RenderProblem() (string, []byte, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return "", nil, err
 	}
-	return false
+	return "application/problem+json", body, nil
+}
+
+// IsCommonType checks if the error uses a problem type registered in DefaultRegistry,
+// which includes the predefined CommonProblemTypes.
+func (p *RFC9457Error) IsCommonType() bool {
+	_, ok := DefaultRegistry.Lookup(p.Type)
+	return ok
 }
 
 // IsDereferenceable checks if the type URI is likely dereferenceable (starts with http/https).
@@ -248,7 +294,56 @@ func (p *RFC9457Error) MarshalJSON() ([]byte, error) {
 	return json.Marshal(result)
 }
 
-// Validate checks if the RFC9457Error follows RFC9457 best practices.
+// UnmarshalJSON implements the json.Unmarshaler interface, the counterpart of MarshalJSON.
+// Unknown top-level members are collected into Extensions. Standard members with the wrong
+// JSON type are ignored rather than failing the decode, as required by RFC9457 Section 3.1.
+func (p *RFC9457Error) UnmarshalJSON(data []byte) error {
+	fields, extensions, err := unmarshalProblem(data)
+	if err != nil {
+		return err
+	}
+	p.setMembers(fields, extensions)
+	return nil
+}
+
+// MarshalXML implements the xml.Marshaler interface, encoding the problem as an
+// application/problem+xml document in the urn:ietf:rfc:7807 namespace as defined in RFC9457 Appendix B.
+// Extension members are encoded as child elements, with arrays represented as <i> elements and nil values
+// as empty elements. It fails for extension members whose names are not valid XML names.
+func (p *RFC9457Error) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalProblemXML(e, p.Type, p.Title, p.Status, p.Detail, p.Instance, p.Extensions)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface, the counterpart of MarshalXML.
+// Unknown child elements are collected into Extensions. Since XML carries no type information,
+// extension values are decoded as strings, arrays or objects; nil values encoded by MarshalXML are decoded as "".
+func (p *RFC9457Error) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	fields, extensions, err := unmarshalProblemXML(d, start)
+	if err != nil {
+		return err
+	}
+	p.setMembers(fields, extensions)
+	return nil
+}
+
+// setMembers stores decoded standard and extension members in the problem.
+func (p *RFC9457Error) setMembers(fields problemFields, extensions map[string]interface{}) {
+	assign(&p.Type, fields.Type)
+	assign(&p.Title, fields.Title)
+	assign(&p.Status, fields.Status)
+	assign(&p.Detail, fields.Detail)
+	assign(&p.Instance, fields.Instance)
+
+	for k, v := range extensions {
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions[k] = v
+	}
+}
+
+// Validate checks if the RFC9457Error follows RFC9457 best practices, including that a problem type
+// registered in DefaultRegistry carries its registered title.
 // Returns nil if valid, or an error describing validation issues.
 func (p *RFC9457Error) Validate() error {
 	var issues []string
@@ -270,6 +365,11 @@ func (p *RFC9457Error) Validate() error {
 		}
 	}
 
+	// Check that a registered type carries its canonical title
+	if err := DefaultRegistry.Validate(p); err != nil {
+		issues = append(issues, err.Error())
+	}
+
 	if len(issues) > 0 {
 		return fmt.Errorf("RFC9457Error validation failed: %s", strings.Join(issues, ", "))
 	}
@@ -279,24 +379,22 @@ func (p *RFC9457Error) Validate() error {
 
 // Helper functions for common HTTP error scenarios following RFC9457
 
-// BadRequestProblem9457 creates a 400 Bad Request problem detail using RFC9457.
-// If title is empty, it defaults to "Bad Request".
+// BadRequestProblem9457 creates a 400 Bad Request problem detail using RFC9457, of the
+// CommonProblemTypes.ValidationError type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Bad Request",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func BadRequestProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Bad Request"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusBadRequest, CommonProblemTypes.ValidationError, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.ValidationError, detail)
 }
 
-// UnauthorizedProblem9457 creates a 401 Unauthorized problem detail using RFC9457.
-// If title is empty, it defaults to "Unauthorized".
+// UnauthorizedProblem9457 creates a 401 Unauthorized problem detail using RFC9457, of the
+// CommonProblemTypes.AuthenticationRequired type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Unauthorized",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func UnauthorizedProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Unauthorized"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusUnauthorized, CommonProblemTypes.AuthenticationRequired, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.AuthenticationRequired, detail)
 }
 
 // PaymentRequiredProblem9457 creates a 402 Payment Required problem detail using RFC9457.
@@ -309,24 +407,22 @@ func PaymentRequiredProblem9457(detail string, title ...string) *RFC9457Error {
 	return NewRFC9457Error(http.StatusPaymentRequired, t, detail)
 }
 
-// ForbiddenProblem9457 creates a 403 Forbidden problem detail using RFC9457.
-// If title is empty, it defaults to "Forbidden".
+// ForbiddenProblem9457 creates a 403 Forbidden problem detail using RFC9457, of the
+// CommonProblemTypes.AuthorizationFailed type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Forbidden",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func ForbiddenProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Forbidden"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusForbidden, CommonProblemTypes.AuthorizationFailed, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.AuthorizationFailed, detail)
 }
 
-// NotFoundProblem9457 creates a 404 Not Found problem detail using RFC9457.
-// If title is empty, it defaults to "Not Found".
+// NotFoundProblem9457 creates a 404 Not Found problem detail using RFC9457, of the
+// CommonProblemTypes.ResourceNotFound type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Not Found",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func NotFoundProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Not Found"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusNotFound, CommonProblemTypes.ResourceNotFound, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.ResourceNotFound, detail)
 }
 
 // MethodNotAllowedProblem9457 creates a 405 Method Not Allowed problem detail using RFC9457.
@@ -369,14 +465,13 @@ func RequestTimeoutProblem9457(detail string, title ...string) *RFC9457Error {
 	return NewRFC9457Error(http.StatusRequestTimeout, t, detail)
 }
 
-// ConflictProblem9457 creates a 409 Conflict problem detail using RFC9457.
-// If title is empty, it defaults to "Conflict".
+// ConflictProblem9457 creates a 409 Conflict problem detail using RFC9457, of the
+// CommonProblemTypes.ResourceConflict type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Conflict",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func ConflictProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Conflict"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusConflict, CommonProblemTypes.ResourceConflict, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.ResourceConflict, detail)
 }
 
 // GoneProblem9457 creates a 410 Gone problem detail using RFC9457.
@@ -469,14 +564,13 @@ func MisdirectedRequestProblem9457(detail string, title ...string) *RFC9457Error
 	return NewRFC9457Error(http.StatusMisdirectedRequest, t, detail)
 }
 
-// UnprocessableEntityProblem9457 creates a 422 Unprocessable Entity problem detail using RFC9457.
-// If title is empty, it defaults to "Unprocessable Entity".
+// UnprocessableEntityProblem9457 creates a 422 Unprocessable Entity problem detail using RFC9457, of the
+// CommonProblemTypes.UnprocessableEntity type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Unprocessable Entity",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func UnprocessableEntityProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Unprocessable Entity"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusUnprocessableEntity, CommonProblemTypes.ValidationError, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.UnprocessableEntity, detail)
 }
 
 // LockedProblem9457 creates a 423 Locked problem detail using RFC9457.
@@ -519,14 +613,13 @@ func PreconditionRequiredProblem9457(detail string, title ...string) *RFC9457Err
 	return NewRFC9457Error(http.StatusPreconditionRequired, t, detail)
 }
 
-// TooManyRequestsProblem9457 creates a 429 Too Many Requests problem detail using RFC9457.
-// If title is empty, it defaults to "Too Many Requests".
+// TooManyRequestsProblem9457 creates a 429 Too Many Requests problem detail using RFC9457, of the
+// CommonProblemTypes.RateLimitExceeded type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Too Many Requests",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func TooManyRequestsProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Too Many Requests"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusTooManyRequests, CommonProblemTypes.RateLimitExceeded, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.RateLimitExceeded, detail)
 }
 
 // RequestHeaderFieldsTooLargeProblem9457 creates a 431 Request Header Fields Too Large problem detail using RFC9457.
@@ -549,14 +642,13 @@ func UnavailableForLegalReasonsProblem9457(detail string, title ...string) *RFC9
 	return NewRFC9457Error(http.StatusUnavailableForLegalReasons, t, detail)
 }
 
-// InternalServerErrorProblem9457 creates a 500 Internal Server Error problem detail using RFC9457.
-// If title is empty, it defaults to "Internal Server Error".
+// InternalServerErrorProblem9457 creates a 500 Internal Server Error problem detail using RFC9457, of the
+// CommonProblemTypes.InternalError type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Internal Server Error",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func InternalServerErrorProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Internal Server Error"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusInternalServerError, CommonProblemTypes.InternalError, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.InternalError, detail)
 }
 
 // NotImplementedProblem9457 creates a 501 Not Implemented problem detail using RFC9457.
@@ -579,14 +671,13 @@ func BadGatewayProblem9457(detail string, title ...string) *RFC9457Error {
 	return NewRFC9457Error(http.StatusBadGateway, t, detail)
 }
 
-// ServiceUnavailableProblem9457 creates a 503 Service Unavailable problem detail using RFC9457.
-// If title is empty, it defaults to "Service Unavailable".
+// ServiceUnavailableProblem9457 creates a 503 Service Unavailable problem detail using RFC9457, of the
+// CommonProblemTypes.ServiceUnavailable type registered in DefaultRegistry.
+// The title parameter is deprecated and ignored: the problem always carries the registered title, "Service Unavailable",
+// since RFC9457 requires every occurrence of a problem type to have the same title.
+// Register a separate problem type to use another title.
 func ServiceUnavailableProblem9457(detail string, title ...string) *RFC9457Error {
-	t := "Service Unavailable"
-	if len(title) > 0 && title[0] != "" {
-		t = title[0]
-	}
-	return NewRFC9457ErrorWithType(http.StatusServiceUnavailable, CommonProblemTypes.ServiceUnavailable, t, detail)
+	return DefaultRegistry.New(CommonProblemTypes.ServiceUnavailable, detail)
 }
 
 // GatewayTimeoutProblem9457 creates a 504 Gateway Timeout problem detail using RFC9457.
@@ -666,9 +757,11 @@ func (p *RFC9457Error) ToHttpError() *HttpError {
 	jsonBytes, err := json.Marshal(p)
 	if err != nil {
 		// If marshaling fails, fall back to just using the detail
-		return New(p.Status, p.Detail, "text/plain")
+		return New(p.Status, p.Detail, "text/plain").WithCause(p.cause).WithMessageKey(p.messageKey, p.messageArgs...)
 	}
-	return New(p.Status, string(jsonBytes), contentType)
+	he := New(p.Status, string(jsonBytes), contentType).WithCause(p.cause).WithMessageKey(p.messageKey, p.messageArgs...)
+	he.problem = p
+	return he
 }
 
 // ToRFC7807Error converts a RFC9457Error to a RFC7807Error for backward compatibility.
@@ -681,6 +774,10 @@ func (p *RFC9457Error) ToRFC7807Error() *RFC7807Error {
 		Detail:     p.Detail,
 		Instance:   p.Instance,
 		Extensions: make(map[string]interface{}),
+		cause:      p.cause,
+
+		messageKey:  p.messageKey,
+		messageArgs: p.messageArgs,
 	}
 
 	// Copy extensions
@@ -690,3 +787,18 @@ func (p *RFC9457Error) ToRFC7807Error() *RFC7807Error {
 
 	return rfc7807
 }
+
+// ToXMLHttpError converts the RFC9457Error to an HttpError with the XML representation of the problem.
+// The resulting HttpError will have the content type set to "application/problem+xml".
+// Problems that have no XML representation, such as those with extension members whose names are
+// not valid XML names, are converted with ToHttpError instead, keeping every member.
+func (p *RFC9457Error) ToXMLHttpError() *HttpError {
+	const contentType = "application/problem+xml"
+	xmlBytes, err := xml.Marshal(p)
+	if err != nil {
+		return p.ToHttpError()
+	}
+	he := New(p.Status, xml.Header+string(xmlBytes), contentType).WithCause(p.cause).WithMessageKey(p.messageKey, p.messageArgs...)
+	he.problem = p
+	return he
+}
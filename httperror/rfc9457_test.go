@@ -2,7 +2,10 @@ package httperror
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -257,6 +260,67 @@ func TestRFC9457Error_ErrorMessage(t *testing.T) {
 	}
 }
 
+// Test RenderProblem method
+func TestRFC9457Error_RenderProblem(t *testing.T) {
+	err := NotFoundProblem9457("User not found").WithExtension("user_id", "123")
+
+	contentType, body, renderErr := err.RenderProblem()
+	if renderErr != nil {
+		t.Fatalf("RenderProblem() error = %v", renderErr)
+	}
+
+	if contentType != "application/problem+json" {
+		t.Errorf("contentType = %s, want application/problem+json", contentType)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("Failed to unmarshal body: %v", err)
+	}
+
+	if result["detail"] != "User not found" {
+		t.Errorf("detail = %v, want %s", result["detail"], "User not found")
+	}
+
+	if result["user_id"] != "123" {
+		t.Errorf("user_id = %v, want %s", result["user_id"], "123")
+	}
+}
+
+// Test WithCause and Unwrap methods
+func TestRFC9457Error_WithCause(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	err := NotFoundProblem9457("User not found").WithCause(cause)
+
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is() = false, want true")
+	}
+
+	if got := err.Unwrap(); got != cause {
+		t.Errorf("Unwrap() = %v, want %v", got, cause)
+	}
+
+	if got, want := err.Error(), "404: Not Found - User not found: sql: no rows in result set"; got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("Failed to marshal: %v", marshalErr)
+	}
+	if strings.Contains(string(data), "sql") {
+		t.Errorf("Cause must not be serialized, got %s", data)
+	}
+
+	if !errors.Is(err.ToHttpError(), cause) {
+		t.Error("ToHttpError() should preserve the cause")
+	}
+
+	if !errors.Is(err.ToRFC7807Error(), cause) {
+		t.Error("ToRFC7807Error() should preserve the cause")
+	}
+}
+
 // Test IsCommonType method
 func TestRFC9457Error_IsCommonType(t *testing.T) {
 	tests := []struct {
@@ -265,6 +329,7 @@ func TestRFC9457Error_IsCommonType(t *testing.T) {
 		expected bool
 	}{
 		{"Validation Error", CommonProblemTypes.ValidationError, true},
+		{"Unprocessable Entity", CommonProblemTypes.UnprocessableEntity, true},
 		{"Authentication Required", CommonProblemTypes.AuthenticationRequired, true},
 		{"Custom Type", "https://example.com/custom-error", false},
 		{"About Blank", "about:blank", false},
@@ -353,6 +418,114 @@ func TestRFC9457Error_MarshalJSON(t *testing.T) {
 }
 
 // Test Validate method
+// Test UnmarshalJSON round trip
+func TestRFC9457Error_UnmarshalJSON_RoundTrip(t *testing.T) {
+	original := NewRFC9457Error(400, "Bad Request", "Invalid input").
+		WithType("https://example.com/problems/validation").
+		WithInstance("/api/users/123").
+		WithExtension("user_id", "123").
+		WithExtension("balance", 30.5).
+		WithExtension("errors", []interface{}{"email is required"})
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var decoded RFC9457Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if decoded.Type != original.Type || decoded.Title != original.Title || decoded.Status != original.Status ||
+		decoded.Detail != original.Detail || decoded.Instance != original.Instance {
+		t.Errorf("Standard members = %+v, want %+v", decoded, *original)
+	}
+
+	if !reflect.DeepEqual(decoded.Extensions, original.Extensions) {
+		t.Errorf("Extensions = %v, want %v", decoded.Extensions, original.Extensions)
+	}
+
+	again, err := json.Marshal(&decoded)
+	if err != nil {
+		t.Fatalf("Failed to marshal decoded problem: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("Round trip = %s, want %s", again, data)
+	}
+}
+
+// Test UnmarshalJSON handling of invalid and unknown members
+func TestRFC9457Error_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    RFC9457Error
+		wantErr bool
+	}{
+		{
+			name:  "Standard members",
+			input: `{"type":"https://example.com/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc"}`,
+			want: RFC9457Error{
+				Type:     "https://example.com/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   403,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+			},
+		},
+		{
+			name:  "Extension members",
+			input: `{"status":403,"balance":30,"accounts":["/account/12345","/account/67890"]}`,
+			want: RFC9457Error{
+				Status: 403,
+				Extensions: map[string]interface{}{
+					"balance":  float64(30),
+					"accounts": []interface{}{"/account/12345", "/account/67890"},
+				},
+			},
+		},
+		{
+			name:  "Wrong JSON types are ignored",
+			input: `{"type":42,"title":["x"],"status":"404","detail":{"a":1},"instance":true}`,
+			want:  RFC9457Error{},
+		},
+		{
+			name:  "Non-integer status is ignored",
+			input: `{"status":404.5,"title":"Not Found"}`,
+			want:  RFC9457Error{Title: "Not Found"},
+		},
+		{
+			name:  "Null members are ignored",
+			input: `{"title":null,"status":null,"trace-id":null}`,
+			want: RFC9457Error{
+				Extensions: map[string]interface{}{"trace-id": nil},
+			},
+		},
+		{
+			name:    "Not an object",
+			input:   `["not","a","problem"]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RFC9457Error
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRFC9457Error_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -483,6 +656,7 @@ func TestProblemHelperFunctions9457(t *testing.T) {
 		fn        func(string, ...string) *RFC9457Error
 		status    int
 		detail    string
+		title     string
 		wantTitle string
 	}{
 		{
@@ -493,11 +667,12 @@ func TestProblemHelperFunctions9457(t *testing.T) {
 			wantTitle: "Bad Request",
 		},
 		{
-			name:      "BadRequestProblem9457 with custom title",
+			name:      "BadRequestProblem9457 ignores custom title",
 			fn:        BadRequestProblem9457,
 			status:    http.StatusBadRequest,
 			detail:    "Invalid input",
-			wantTitle: "Validation Error",
+			title:     "Validation Error",
+			wantTitle: "Bad Request",
 		},
 		{
 			name:      "NotFoundProblem9457",
@@ -507,11 +682,12 @@ func TestProblemHelperFunctions9457(t *testing.T) {
 			wantTitle: "Not Found",
 		},
 		{
-			name:      "NotFoundProblem9457 with custom title",
+			name:      "NotFoundProblem9457 ignores custom title",
 			fn:        NotFoundProblem9457,
 			status:    http.StatusNotFound,
 			detail:    "Resource not found",
-			wantTitle: "Missing Resource",
+			title:     "Missing Resource",
+			wantTitle: "Not Found",
 		},
 		{
 			name:      "ForbiddenProblem9457",
@@ -560,14 +736,9 @@ func TestProblemHelperFunctions9457(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var err *RFC9457Error
-			if tt.wantTitle != "Bad Request" && tt.wantTitle != "Not Found" &&
-				tt.wantTitle != "Forbidden" && tt.wantTitle != "Unauthorized" &&
-				tt.wantTitle != "Internal Server Error" && tt.wantTitle != "Conflict" &&
-				tt.wantTitle != "Too Many Requests" && tt.wantTitle != "Service Unavailable" {
-				// Custom title case
-				err = tt.fn(tt.detail, tt.wantTitle)
+			if tt.title != "" {
+				err = tt.fn(tt.detail, tt.title)
 			} else {
-				// Default title case
 				err = tt.fn(tt.detail)
 			}
 
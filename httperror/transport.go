@@ -0,0 +1,110 @@
+package httperror
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// ResponseError is returned by Transport for error responses.
+// It wraps the typed error produced by FromResponse and keeps the original *http.Response
+// reachable for callers that need its headers. The response body has already been read
+// and closed; Response.Body replays the bytes that were read.
+type ResponseError struct {
+	Response *http.Response
+	Err      error
+}
+
+// Error returns the message of the wrapped error.
+func (e *ResponseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the typed error decoded from the response, such as an *RFC9457Error or *HttpError.
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// Transport is an http.RoundTripper that converts error responses into Go errors.
+// Responses with a status code of 400 or above are decoded with FromResponse and returned
+// as a *ResponseError, so http.Client.Do returns an error that can be inspected with errors.As.
+// Redirects and other non-error responses are returned unchanged, as are responses whose
+// status code has been opted out with WithPassthroughStatus.
+type Transport struct {
+	base        http.RoundTripper
+	passthrough map[int]bool
+	maxBodySize int64
+}
+
+// TransportOption configures a Transport.
+type TransportOption func(*Transport)
+
+// WithPassthroughStatus returns responses with the given status codes unchanged instead of converting them
+// into errors. For example, WithPassthroughStatus(http.StatusNotFound) treats 404 as a normal response.
+func WithPassthroughStatus(codes ...int) TransportOption {
+	return func(t *Transport) {
+		for _, code := range codes {
+			t.passthrough[code] = true
+		}
+	}
+}
+
+// WithMaxBodySize sets the maximum number of bytes read from an error response body.
+// It defaults to DefaultMaxResponseBodySize.
+func WithMaxBodySize(n int64) TransportOption {
+	return func(t *Transport) {
+		if n > 0 {
+			t.maxBodySize = n
+		}
+	}
+}
+
+// NewTransport creates a new Transport that sends requests through base.
+// If base is nil, http.DefaultTransport is used.
+func NewTransport(base http.RoundTripper, opts ...TransportOption) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{
+		base:        base,
+		passthrough: make(map[int]bool),
+		maxBodySize: DefaultMaxResponseBodySize,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 400 || t.passthrough[resp.StatusCode] {
+		return resp, nil
+	}
+
+	// Read the body once, so it can be decoded and still be replayed from the ResponseError
+	var body []byte
+	if resp.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, t.maxBodySize))
+		resp.Body.Close()
+		if err != nil {
+			resp.Body = http.NoBody
+			return nil, &ResponseError{
+				Response: resp,
+				Err:      New(resp.StatusCode, http.StatusText(resp.StatusCode)).WithCause(err),
+			}
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	decoded := FromResponse(resp, t.maxBodySize)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// A RoundTripper must not return both a response and an error, so the response
+	// is only reachable through the ResponseError
+	return nil, &ResponseError{Response: resp, Err: decoded}
+}
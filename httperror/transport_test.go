@@ -0,0 +1,116 @@
+package httperror
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newProblemServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("OK"))
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/missing":
+			http.Error(w, "no such account", http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"type":"https://example.com/rate-limited","title":"Too Many Requests","status":429,"limit":100}`))
+		}
+	}))
+}
+
+func TestTransport_Problem(t *testing.T) {
+	server := newProblemServer()
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	resp, err := client.Get(server.URL + "/limited")
+	if resp != nil {
+		resp.Body.Close()
+		t.Fatal("Expected no response for an error status")
+	}
+
+	var problem *RFC9457Error
+	if !errors.As(err, &problem) {
+		t.Fatalf("Expected *RFC9457Error, got %T: %v", err, err)
+	}
+	if problem.Type != "https://example.com/rate-limited" {
+		t.Errorf("Type = %s, want https://example.com/rate-limited", problem.Type)
+	}
+	if problem.Extensions["limit"] != float64(100) {
+		t.Errorf("limit = %v, want 100", problem.Extensions["limit"])
+	}
+
+	var re *ResponseError
+	if !errors.As(err, &re) {
+		t.Fatalf("Expected *ResponseError, got %T", err)
+	}
+	if re.Response.Header.Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %s, want 60", re.Response.Header.Get("Retry-After"))
+	}
+	body, readErr := io.ReadAll(re.Response.Body)
+	if readErr != nil {
+		t.Fatalf("Failed to read replayed body: %v", readErr)
+	}
+	if len(body) == 0 {
+		t.Error("Expected the response body to be replayable")
+	}
+}
+
+func TestTransport_PlainText(t *testing.T) {
+	server := newProblemServer()
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	_, err := client.Get(server.URL + "/missing")
+
+	var he *HttpError
+	if !errors.As(err, &he) {
+		t.Fatalf("Expected *HttpError, got %T: %v", err, err)
+	}
+	if he.Code != http.StatusNotFound || he.Message != "no such account" {
+		t.Errorf("Expected 404 'no such account', got %d %q", he.Code, he.Message)
+	}
+}
+
+func TestTransport_PassthroughStatus(t *testing.T) {
+	server := newProblemServer()
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, WithPassthroughStatus(http.StatusNotFound))}
+	resp, err := client.Get(server.URL + "/missing")
+	if err != nil {
+		t.Fatalf("Expected 404 to pass through, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestTransport_SuccessAndRedirect(t *testing.T) {
+	server := newProblemServer()
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	for _, path := range []string{"/ok", "/redirect"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: unexpected error %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || string(body) != "OK" {
+			t.Errorf("GET %s: expected 200 OK, got %d %q", path, resp.StatusCode, body)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package httperror
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes a single invalid member of a request, in the shape of the "errors" extension
+// used by the validation example in RFC9457 Section 3.
+// Exactly one of Pointer and Parameter identifies the location of the invalid value.
+type FieldError struct {
+	// Pointer is a JSON Pointer (RFC6901) to the invalid member of the request body,
+	// in URI fragment form as in the RFC9457 examples, such as "#/profile/color".
+	Pointer string `json:"pointer,omitempty"`
+
+	// Parameter is the name of the invalid path or query parameter, as told apart by In.
+	Parameter string `json:"parameter,omitempty"`
+
+	// In is the location of the invalid parameter: "path" or "query". It is empty for body members.
+	In string `json:"in,omitempty"`
+
+	// Detail is a human-readable explanation of why the value is invalid.
+	Detail string `json:"detail"`
+
+	// Code is an optional machine-readable identifier of the failed rule, such as "required".
+	Code string `json:"code,omitempty"`
+}
+
+// ValidationErrors collects the field-level failures of a request.
+// It implements error and ProblemDetailer, so it can be returned from a handler directly
+// and is rendered as a 400 Bad Request problem with an "errors" extension.
+// Use ToRFC9457Error to customize the problem, for example to respond with 422 Unprocessable Entity.
+type ValidationErrors struct {
+	errors []FieldError
+}
+
+// NewValidationErrors creates a new, empty ValidationErrors.
+func NewValidationErrors() *ValidationErrors {
+	return &ValidationErrors{}
+}
+
+// Add records an invalid member of the request body and returns the ValidationErrors for method chaining.
+// The pointer is a JSON Pointer as built by JSONPointer; pointers without the "#" prefix, such as "/age", are
+// converted to URI fragment form. The code parameter is optional.
+func (v *ValidationErrors) Add(pointer, detail string, code ...string) *ValidationErrors {
+	if strings.HasPrefix(pointer, "/") || pointer == "" {
+		pointer = "#" + (&url.URL{Fragment: pointer}).EscapedFragment()
+	}
+	v.errors = append(v.errors, FieldError{Pointer: pointer, Detail: detail, Code: first(code)})
+	return v
+}
+
+// AddParameter records an invalid query parameter and returns the ValidationErrors for method chaining.
+// The code parameter is optional.
+func (v *ValidationErrors) AddParameter(name, detail string, code ...string) *ValidationErrors {
+	v.errors = append(v.errors, FieldError{Parameter: name, In: "query", Detail: detail, Code: first(code)})
+	return v
+}
+
+// AddPathParameter records an invalid path parameter, such as the id of "/users/{id}",
+// and returns the ValidationErrors for method chaining. The code parameter is optional.
+func (v *ValidationErrors) AddPathParameter(name, detail string, code ...string) *ValidationErrors {
+	v.errors = append(v.errors, FieldError{Parameter: name, In: "path", Detail: detail, Code: first(code)})
+	return v
+}
+
+// Merge appends the failures of other and returns the ValidationErrors for method chaining.
+func (v *ValidationErrors) Merge(other *ValidationErrors) *ValidationErrors {
+	if other != nil {
+		v.errors = append(v.errors, other.errors...)
+	}
+	return v
+}
+
+// Len returns the number of recorded failures.
+func (v *ValidationErrors) Len() int {
+	if v == nil {
+		return 0
+	}
+	return len(v.errors)
+}
+
+// IsEmpty reports whether no failures have been recorded.
+// It is typically checked before returning the ValidationErrors from a handler.
+func (v *ValidationErrors) IsEmpty() bool {
+	return v.Len() == 0
+}
+
+// Errors returns a copy of the recorded failures in the order they were added.
+func (v *ValidationErrors) Errors() []FieldError {
+	if v == nil {
+		return nil
+	}
+	return append([]FieldError(nil), v.errors...)
+}
+
+// Err returns the ValidationErrors as an error, or nil if it is empty.
+// This avoids returning a non-nil error interface holding an empty ValidationErrors.
+func (v *ValidationErrors) Err() error {
+	if v.IsEmpty() {
+		return nil
+	}
+	return v
+}
+
+// Error returns a summary of the recorded failures, implementing the error interface.
+func (v *ValidationErrors) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed")
+	for i, e := range v.Errors() {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		location := e.Pointer
+		if location == "" {
+			location = e.Parameter
+		}
+		b.WriteString(location + ": " + e.Detail)
+	}
+	return b.String()
+}
+
+// StatusCode returns 400 Bad Request, implementing the StatusCoder interface.
+func (v *ValidationErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ProblemDetails returns the failures as a 400 Bad Request problem, implementing the ProblemDetailer interface.
+func (v *ValidationErrors) ProblemDetails() *RFC9457Error {
+	return v.ToRFC9457Error(http.StatusBadRequest)
+}
+
+// ToRFC9457Error converts the failures into a validation problem with the given status code,
+// usually 400 Bad Request or 422 Unprocessable Entity, which use the CommonProblemTypes.ValidationError and
+// CommonProblemTypes.UnprocessableEntity types registered in DefaultRegistry.
+// Any other status uses the "about:blank" type with the status text as title.
+// The failures are listed in the "errors" extension.
+// The detail parameter is optional and defaults to a summary of the number of failures.
+func (v *ValidationErrors) ToRFC9457Error(status int, detail ...string) *RFC9457Error {
+	d := first(detail)
+	if d == "" {
+		d = "The request is not valid."
+		if n := v.Len(); n == 1 {
+			d = "The request has 1 invalid field."
+		} else if n > 1 {
+			d = "The request has " + strconv.Itoa(n) + " invalid fields."
+		}
+	}
+
+	var p *RFC9457Error
+	switch status {
+	case http.StatusBadRequest:
+		p = BadRequestProblem9457(d)
+	case http.StatusUnprocessableEntity:
+		p = UnprocessableEntityProblem9457(d)
+	default:
+		p = NewRFC9457Error(status, http.StatusText(status), d)
+	}
+
+	errs := v.Errors()
+	if errs == nil {
+		errs = []FieldError{}
+	}
+	return p.WithExtension("errors", errs).WithCause(v)
+}
+
+// JSONPointer builds a JSON Pointer (RFC6901) in URI fragment form from reference tokens,
+// escaping "~" and "/" within tokens. For example, JSONPointer("profile", "color") returns "#/profile/color".
+func JSONPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return "#" + (&url.URL{Fragment: b.String()}).EscapedFragment()
+}
+
+// first returns the first value, or "" if there is none.
+func first(values []string) string {
+	if len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package httperror
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJSONPointer(t *testing.T) {
+	tests := []struct {
+		tokens   []string
+		expected string
+	}{
+		{nil, "#"},
+		{[]string{"age"}, "#/age"},
+		{[]string{"profile", "color"}, "#/profile/color"},
+		{[]string{"items", "0", "sku"}, "#/items/0/sku"},
+		{[]string{"a/b", "m~n"}, "#/a~1b/m~0n"},
+		{[]string{"first name"}, "#/first%20name"},
+	}
+
+	for _, tt := range tests {
+		if got := JSONPointer(tt.tokens...); got != tt.expected {
+			t.Errorf("JSONPointer(%q) = %q, want %q", tt.tokens, got, tt.expected)
+		}
+	}
+}
+
+func TestValidationErrors_ToRFC9457Error(t *testing.T) {
+	v := NewValidationErrors().
+		Add(JSONPointer("age"), "must be a positive integer").
+		Add("/profile/color", "must be 'green', 'red' or 'blue'", "oneof").
+		AddParameter("page", "must be at least 1", "min")
+
+	p := v.ToRFC9457Error(http.StatusUnprocessableEntity)
+	if p.Status != http.StatusUnprocessableEntity {
+		t.Errorf("Status = %d, want %d", p.Status, http.StatusUnprocessableEntity)
+	}
+	if p.Type != CommonProblemTypes.UnprocessableEntity {
+		t.Errorf("Type = %s, want %s", p.Type, CommonProblemTypes.UnprocessableEntity)
+	}
+	if p.Detail != "The request has 3 invalid fields." {
+		t.Errorf("Detail = %q", p.Detail)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Failed to marshal problem: %v", err)
+	}
+
+	var body struct {
+		Errors []map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("Failed to unmarshal problem: %v", err)
+	}
+
+	expected := []map[string]string{
+		{"pointer": "#/age", "detail": "must be a positive integer"},
+		{"pointer": "#/profile/color", "detail": "must be 'green', 'red' or 'blue'", "code": "oneof"},
+		{"parameter": "page", "in": "query", "detail": "must be at least 1", "code": "min"},
+	}
+	if len(body.Errors) != len(expected) {
+		t.Fatalf("errors = %v, want %v", body.Errors, expected)
+	}
+	for i := range expected {
+		for key, value := range expected[i] {
+			if body.Errors[i][key] != value {
+				t.Errorf("errors[%d][%q] = %q, want %q", i, key, body.Errors[i][key], value)
+			}
+		}
+		if len(body.Errors[i]) != len(expected[i]) {
+			t.Errorf("errors[%d] = %v, want %v", i, body.Errors[i], expected[i])
+		}
+	}
+}
+
+func TestValidationErrors_ToRFC9457ErrorTypes(t *testing.T) {
+	v := NewValidationErrors().Add(JSONPointer("age"), "must be a positive integer")
+
+	tests := []struct {
+		status   int
+		typeURI  string
+		expected string
+	}{
+		{http.StatusBadRequest, CommonProblemTypes.ValidationError, "Bad Request"},
+		{http.StatusUnprocessableEntity, CommonProblemTypes.UnprocessableEntity, "Unprocessable Entity"},
+		{http.StatusConflict, "about:blank", "Conflict"},
+	}
+
+	for _, tt := range tests {
+		p := v.ToRFC9457Error(tt.status)
+		if p.Status != tt.status || p.Type != tt.typeURI || p.Title != tt.expected {
+			t.Errorf("ToRFC9457Error(%d) = %d %s %q, want %d %s %q", tt.status, p.Status, p.Type, p.Title, tt.status, tt.typeURI, tt.expected)
+		}
+		if err := p.Validate(); err != nil {
+			t.Errorf("ToRFC9457Error(%d).Validate() error = %v", tt.status, err)
+		}
+	}
+}
+
+func TestValidationErrors_UnprocessableEntityRoundTrip(t *testing.T) {
+	p := NewValidationErrors().Add(JSONPointer("age"), "must be a positive integer").ToRFC9457Error(http.StatusUnprocessableEntity)
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Failed to marshal problem: %v", err)
+	}
+	var decoded RFC9457Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal problem: %v", err)
+	}
+	if decoded.Type != CommonProblemTypes.UnprocessableEntity || decoded.Status != http.StatusUnprocessableEntity {
+		t.Errorf("Decoded %d %s, want %d %s", decoded.Status, decoded.Type, http.StatusUnprocessableEntity, CommonProblemTypes.UnprocessableEntity)
+	}
+	if err := DefaultRegistry.Validate(&decoded); err != nil {
+		t.Errorf("DefaultRegistry.Validate() error = %v", err)
+	}
+}
+
+func TestValidationErrors_XML(t *testing.T) {
+	p := NewValidationErrors().Add(JSONPointer("age"), "must be a positive integer").ToRFC9457Error(http.StatusBadRequest)
+
+	data, err := xml.Marshal(p)
+	if err != nil {
+		t.Fatalf("Failed to marshal problem: %v", err)
+	}
+	if !strings.Contains(string(data), "<errors><i><detail>must be a positive integer</detail><pointer>#/age</pointer></i></errors>") {
+		t.Errorf("Unexpected XML: %s", data)
+	}
+}
+
+func TestValidationErrors_EmptyAndMerge(t *testing.T) {
+	var nilErrors *ValidationErrors
+	if !nilErrors.IsEmpty() || nilErrors.Err() != nil {
+		t.Error("nil ValidationErrors should be empty")
+	}
+
+	v := NewValidationErrors()
+	if !v.IsEmpty() || v.Err() != nil {
+		t.Error("new ValidationErrors should be empty")
+	}
+
+	body := NewValidationErrors().Add(JSONPointer("name"), "is required", "required")
+	query := NewValidationErrors().AddParameter("sort", "is not a sortable field")
+	v.Merge(body).Merge(query).Merge(nil)
+
+	if v.Len() != 2 || v.IsEmpty() {
+		t.Fatalf("Len() = %d, want 2", v.Len())
+	}
+	if body.Len() != 1 || query.Len() != 1 {
+		t.Error("Merge should not modify its argument")
+	}
+	if got := v.Error(); got != "validation failed: #/name: is required; sort: is not a sortable field" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	var err error = v.Err()
+	var pd ProblemDetailer
+	if !errors.As(err, &pd) || pd.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("Expected a 400 ProblemDetailer, got %v", err)
+	}
+	if errs := pd.ProblemDetails().Extensions["errors"].([]FieldError); len(errs) != 2 {
+		t.Errorf("errors extension = %v, want 2 entries", errs)
+	}
+}
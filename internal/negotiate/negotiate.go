@@ -0,0 +1,131 @@
+// Package negotiate implements HTTP proactive content negotiation as described in RFC 9110 Section 12.5.
+// It is shared by the httperror and render packages to select media types and languages.
+package negotiate
+
+import (
+	"strconv"
+	"strings"
+)
+
+// preference is a single entry of an Accept header.
+type preference struct {
+	value string
+	q     float64
+}
+
+// parse splits a header value into its entries and their quality values.
+// Entries with an invalid quality value are treated as q=0.
+func parse(header string) []preference {
+	var prefs []preference
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			name, raw, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.ToLower(strings.TrimSpace(name)) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		prefs = append(prefs, preference{value: value, q: q})
+	}
+	return prefs
+}
+
+// ContentType returns the offer that best matches the Accept header value, or "" if none is acceptable.
+// Offers must be media types without parameters, listed in the server's order of preference,
+// which breaks ties between equally acceptable offers. An empty header accepts the first offer.
+func ContentType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	prefs := parse(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q := mediaQuality(prefs, strings.ToLower(offer))
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaQuality returns the quality value of the most specific media range matching offer.
+func mediaQuality(prefs []preference, offer string) float64 {
+	offerType, offerSub, _ := strings.Cut(offer, "/")
+	q, specificity := 0.0, -1
+	for _, p := range prefs {
+		rangeType, rangeSub, _ := strings.Cut(p.value, "/")
+		s := -1
+		switch {
+		case rangeType == offerType && rangeSub == offerSub:
+			s = 2
+		case rangeType == offerType && rangeSub == "*":
+			s = 1
+		case rangeType == "*" && rangeSub == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = p.q, s
+		}
+	}
+	return q
+}
+
+// Language returns the offer that best matches the Accept-Language header value, or "" if none is acceptable.
+// Offers are language tags such as "en" or "ko-KR", listed in the server's order of preference.
+// A language range matches an offer if it equals the offer or is a prefix of it followed by "-",
+// and an offer also matches a more specific range of its own language (so "ko" satisfies "ko-KR").
+// An empty header accepts the first offer.
+func Language(acceptLanguage string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(acceptLanguage) == "" {
+		return offers[0]
+	}
+
+	prefs := parse(acceptLanguage)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q := languageQuality(prefs, strings.ToLower(offer))
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// languageQuality returns the quality value of the most specific language range matching offer.
+func languageQuality(prefs []preference, offer string) float64 {
+	q, specificity := 0.0, -1
+	for _, p := range prefs {
+		s := -1
+		switch {
+		case p.value == offer:
+			s = 3
+		case strings.HasPrefix(offer, p.value+"-"):
+			s = 2
+		case strings.HasPrefix(p.value, offer+"-"):
+			s = 1
+		case p.value == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = p.q, s
+		}
+	}
+	return q
+}
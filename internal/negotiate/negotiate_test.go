@@ -0,0 +1,58 @@
+package negotiate
+
+import "testing"
+
+func TestContentType(t *testing.T) {
+	offers := []string{"application/problem+json", "application/problem+xml", "text/html", "text/plain"}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"Empty header", "", "application/problem+json"},
+		{"Wildcard", "*/*", "application/problem+json"},
+		{"Exact match", "application/problem+xml", "application/problem+xml"},
+		{"Browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html"},
+		{"Quality values", "text/plain;q=0.5, application/problem+xml;q=0.9", "application/problem+xml"},
+		{"Subtype wildcard", "text/*", "text/html"},
+		{"Specific range overrides wildcard", "text/*;q=0.9, text/html;q=0.1", "text/plain"},
+		{"Explicitly refused", "application/problem+json;q=0, */*;q=0.1", "application/problem+xml"},
+		{"Nothing acceptable", "image/png", ""},
+		{"Case insensitive", "Text/HTML", "text/html"},
+		{"Invalid quality", "text/html;q=abc, text/plain", "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContentType(tt.accept, offers...); got != tt.want {
+				t.Errorf("ContentType(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	offers := []string{"en", "ko"}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"Empty header", "", "en"},
+		{"Exact match", "ko", "ko"},
+		{"Regional range", "ko-KR,ko;q=0.9,en-US;q=0.8,en;q=0.7", "ko"},
+		{"Quality values", "ko;q=0.3, en;q=0.8", "en"},
+		{"Wildcard", "*", "en"},
+		{"Nothing acceptable", "fr", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Language(tt.accept, offers...); got != tt.want {
+				t.Errorf("Language(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
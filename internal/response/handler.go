@@ -0,0 +1,68 @@
+package response
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/render"
+)
+
+// ErrorHandler runs error-returning handlers and handles their errors and panics.
+// It holds the settings shared by httpwrap and chiwrap, which only differ in how the matched route is found.
+type ErrorHandler struct {
+	Renderer         render.Renderer                // Renderer converting errors into responses
+	ErrorCallback    func(err error)                // Called with every handled error; must not be nil
+	EventCallback    func(event *render.ErrorEvent) // Called after ErrorCallback, if not nil
+	AbortAfterCommit bool                           // Abort the connection on errors after the response was committed
+	Route            func(request *http.Request) string
+}
+
+// Wrap converts an error-returning handler into an http.HandlerFunc.
+// Returned errors are rendered through the Renderer and reported to the callbacks. Panics are recovered
+// and handled as a *httperror.PanicError, except http.ErrAbortHandler, which is re-panicked for net/http.
+// Errors after the response was committed are only reported, wrapped in a *render.CommittedError.
+func (h *ErrorHandler) Wrap(handler func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, request *http.Request) {
+		writer := Wrap(w)
+		start := time.Now()
+		defer func() {
+			if v := recover(); v != nil {
+				if httperror.IsAbortPanic(v) {
+					panic(v)
+				}
+				h.handle(writer, request, start, httperror.NewPanicError(v))
+			}
+		}()
+		if err := handler(writer, request); err != nil {
+			h.handle(writer, request, start, err)
+		}
+	}
+}
+
+// handle renders the error unless the response was already committed, and reports it to the error and
+// event callbacks. start is the time the handler was called.
+func (h *ErrorHandler) handle(writer *Writer, request *http.Request, start time.Time, err error) {
+	event := &render.ErrorEvent{Request: request, Route: h.Route(request)}
+	if writer.Committed() {
+		event.Err = &render.CommittedError{Err: err}
+		event.Status = writer.Status()
+		event.Committed = true
+	} else {
+		resp := h.Renderer.Render(request, err)
+		render.Write(writer, resp)
+		event.Err = render.Report(err, resp)
+		event.Status = resp.Status
+		event.ProblemType = resp.ProblemType
+		event.Reference = resp.Reference
+	}
+	event.Latency = time.Since(start)
+
+	h.ErrorCallback(event.Err)
+	if h.EventCallback != nil {
+		h.EventCallback(event)
+	}
+	if event.Committed && h.AbortAfterCommit {
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,70 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/render"
+)
+
+func TestErrorHandler_Wrap(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        func(w http.ResponseWriter, r *http.Request) error
+		expectedStatus int
+		expectedErr    func(err error) bool
+		committed      bool
+	}{
+		{
+			name:           "Error",
+			handler:        func(w http.ResponseWriter, r *http.Request) error { return httperror.NotFound("missing") },
+			expectedStatus: http.StatusNotFound,
+			expectedErr:    func(err error) bool { var he *httperror.HttpError; return errors.As(err, &he) },
+		},
+		{
+			name:           "Panic",
+			handler:        func(w http.ResponseWriter, r *http.Request) error { panic("boom") },
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    func(err error) bool { var pe *httperror.PanicError; return errors.As(err, &pe) },
+		},
+		{
+			name: "Error after commit",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusAccepted)
+				return httperror.BadRequest("too late")
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedErr:    func(err error) bool { var ce *render.CommittedError; return errors.As(err, &ce) },
+			committed:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported error
+			var event *render.ErrorEvent
+			h := &ErrorHandler{
+				Renderer:      render.New(),
+				ErrorCallback: func(err error) { reported = err },
+				EventCallback: func(e *render.ErrorEvent) { event = e },
+				Route:         func(r *http.Request) string { return "/route" },
+			}
+
+			w := httptest.NewRecorder()
+			h.Wrap(tt.handler)(w, httptest.NewRequest("GET", "/", nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Status = %d, want %d", w.Code, tt.expectedStatus)
+			}
+			if !tt.expectedErr(reported) {
+				t.Errorf("Reported error %T: %v", reported, reported)
+			}
+			if event == nil || event.Route != "/route" || event.Status != tt.expectedStatus || event.Committed != tt.committed {
+				t.Errorf("Event = %+v", event)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package response tracks whether a handler has started writing its response.
+// It is shared by httpwrap and chiwrap, which must not render an error over a response that was already sent,
+// along with the ErrorHandler that renders and reports the errors of their handlers.
+package response
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Writer wraps an http.ResponseWriter and records whether the response has been committed,
+// that is whether its status line and headers may have been sent to the client.
+//
+// Writer implements http.Flusher, http.Hijacker and io.ReaderFrom, and unwraps to the underlying ResponseWriter
+// so that http.ResponseController can reach the other optional features of the connection.
+type Writer struct {
+	http.ResponseWriter
+	committed bool
+	status    int
+}
+
+// Wrap returns a Writer wrapping w, or w itself if it is already a Writer.
+func Wrap(w http.ResponseWriter) *Writer {
+	if rw, ok := w.(*Writer); ok {
+		return rw
+	}
+	return &Writer{ResponseWriter: w}
+}
+
+// Committed reports whether the response has been committed.
+func (w *Writer) Committed() bool {
+	return w.committed
+}
+
+// Status returns the status code of the committed response, or 0 if the response has not been committed.
+func (w *Writer) Status() int {
+	return w.status
+}
+
+// commit records that the response was committed with the given status code, unless it already was.
+func (w *Writer) commit(status int) {
+	if !w.committed {
+		w.committed = true
+		w.status = status
+	}
+}
+
+// WriteHeader sends the status code. Informational 1xx responses other than 101 Switching Protocols
+// may be followed by the final response, so they do not commit it.
+func (w *Writer) WriteHeader(code int) {
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		w.commit(code)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the body, committing the response with 200 OK if no status code was sent.
+func (w *Writer) Write(b []byte) (int, error) {
+	w.commit(http.StatusOK)
+	return w.ResponseWriter.Write(b)
+}
+
+// ReadFrom copies the body from r, committing the response with 200 OK if no status code was sent.
+// It delegates to the underlying ResponseWriter if it implements io.ReaderFrom, so that io.Copy and
+// http.ServeContent keep using sendfile for files.
+func (w *Writer) ReadFrom(r io.Reader) (int64, error) {
+	w.commit(http.StatusOK)
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(w.ResponseWriter, r)
+}
+
+// Flush sends any buffered data to the client, committing the response.
+func (w *Writer) Flush() {
+	_ = w.FlushError()
+}
+
+// FlushError is like Flush but returns an error if the underlying ResponseWriter does not support flushing.
+// http.ResponseController uses it in preference to Flush.
+func (w *Writer) FlushError() error {
+	err := http.NewResponseController(w.ResponseWriter).Flush()
+	if err == nil {
+		w.commit(http.StatusOK)
+	}
+	return err
+}
+
+// Hijack lets the handler take over the connection. The response is considered committed,
+// since nothing can be rendered once the connection is hijacked.
+func (w *Writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.commit(http.StatusSwitchingProtocols)
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by http.ResponseController.
+func (w *Writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
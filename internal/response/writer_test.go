@@ -0,0 +1,137 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter_Committed(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(w *Writer)
+		expected bool
+	}{
+		{"Nothing written", func(w *Writer) { w.Header().Set("X-Test", "1") }, false},
+		{"Informational status", func(w *Writer) { w.WriteHeader(http.StatusEarlyHints) }, false},
+		{"Final status", func(w *Writer) { w.WriteHeader(http.StatusAccepted) }, true},
+		{"Switching protocols", func(w *Writer) { w.WriteHeader(http.StatusSwitchingProtocols) }, true},
+		{"Body", func(w *Writer) { w.Write([]byte("partial")) }, true},
+		{"Flush", func(w *Writer) { w.Flush() }, true},
+		{"ReadFrom", func(w *Writer) { w.ReadFrom(strings.NewReader("partial")) }, true},
+		{"ResponseController flush", func(w *Writer) { http.NewResponseController(w).Flush() }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := Wrap(httptest.NewRecorder())
+			tt.write(w)
+			if w.Committed() != tt.expected {
+				t.Errorf("Committed() = %v, want %v", w.Committed(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriter_Status(t *testing.T) {
+	w := Wrap(httptest.NewRecorder())
+	w.WriteHeader(http.StatusEarlyHints)
+	if w.Status() != 0 {
+		t.Errorf("Status() = %d before the final status, want 0", w.Status())
+	}
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte("created"))
+	if w.Status() != http.StatusCreated {
+		t.Errorf("Status() = %d, want 201", w.Status())
+	}
+
+	w = Wrap(httptest.NewRecorder())
+	w.Write([]byte("ok"))
+	if w.Status() != http.StatusOK {
+		t.Errorf("Status() = %d after an implicit status, want 200", w.Status())
+	}
+}
+
+func TestWrap_Idempotent(t *testing.T) {
+	w := Wrap(httptest.NewRecorder())
+	if Wrap(w) != w {
+		t.Error("Wrap() should return an existing Writer as is")
+	}
+}
+
+func TestWriter_Hijack(t *testing.T) {
+	// httptest.ResponseRecorder cannot be hijacked
+	w := Wrap(httptest.NewRecorder())
+	if _, _, err := w.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack() error = %v, want http.ErrNotSupported", err)
+	}
+	if w.Committed() {
+		t.Error("A failed Hijack should not commit the response")
+	}
+}
+
+func TestWriter_ResponseController(t *testing.T) {
+	var controllerErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := Wrap(w)
+		controllerErr = http.NewResponseController(rw).SetWriteDeadline(time.Now().Add(time.Minute))
+		conn, _, err := rw.Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		defer conn.Close()
+		if !rw.Committed() {
+			t.Error("Expected a hijacked response to be committed")
+		}
+		conn.Write([]byte("HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n"))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	if controllerErr != nil {
+		t.Errorf("SetWriteDeadline() error = %v, want it to reach the underlying ResponseWriter", controllerErr)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", resp.StatusCode)
+	}
+}
+
+// readerFromRecorder is a ResponseRecorder that records whether ReadFrom was called.
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	called bool
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.called = true
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestWriter_ReadFrom(t *testing.T) {
+	rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w := Wrap(rec)
+
+	n, err := w.ReadFrom(strings.NewReader("body"))
+	if err != nil || n != 4 {
+		t.Fatalf("ReadFrom() = %d, %v, want 4, nil", n, err)
+	}
+	if !rec.called {
+		t.Error("ReadFrom was not delegated to the underlying ResponseWriter")
+	}
+	if !w.Committed() || w.Status() != http.StatusOK {
+		t.Errorf("Committed() = %v, Status() = %d, want true, %d", w.Committed(), w.Status(), http.StatusOK)
+	}
+	if rec.Body.String() != "body" {
+		t.Errorf("Body = %q, want %q", rec.Body.String(), "body")
+	}
+}
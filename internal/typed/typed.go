@@ -0,0 +1,68 @@
+// Package typed encodes and writes the responses of the typed handlers of the wrappers.
+// It is shared by httpwrap, chiwrap and fiberwrap so that typed handlers respond identically on every router.
+package typed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+// ContentType is the Content-Type of encoded response bodies.
+const ContentType = "application/json"
+
+// Encode returns the status code and JSON body of the response value of a typed handler.
+// The status code is 200 OK unless resp implements httperror.StatusCoder. Nil responses and
+// 204 No Content or 304 Not Modified responses have no body, which is reported as a nil body;
+// nil responses without a status code of their own are sent as 204 No Content.
+func Encode(resp interface{}) (int, []byte, error) {
+	status := http.StatusOK
+	if sc, ok := resp.(httperror.StatusCoder); ok && !isNil(resp) {
+		status = sc.StatusCode()
+		if status < 100 || status > 999 {
+			return 0, nil, fmt.Errorf("typed: invalid response status code %d", status)
+		}
+	}
+	if isNil(resp) || status == http.StatusNoContent || status == http.StatusNotModified {
+		if status == http.StatusOK {
+			status = http.StatusNoContent
+		}
+		return status, nil, nil
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return 0, nil, fmt.Errorf("typed: failed to encode response: %w", err)
+	}
+	return status, body, nil
+}
+
+// isNil reports whether v is nil or a nil pointer. Nil slices and maps are encoded as null,
+// since an empty list is still a response.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// Write writes the response value of a typed handler to writer, as encoded by Encode.
+// It is used by the net/http based wrappers; fiberwrap writes the encoded response to the Fiber context.
+func Write(writer http.ResponseWriter, resp interface{}) error {
+	status, body, err := Encode(resp)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		writer.WriteHeader(status)
+		return nil
+	}
+	writer.Header().Set("Content-Type", ContentType)
+	writer.WriteHeader(status)
+	_, err = writer.Write(body)
+	return err
+}
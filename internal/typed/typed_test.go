@@ -0,0 +1,90 @@
+package typed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type created struct {
+	ID int `json:"id"`
+}
+
+func (created) StatusCode() int { return http.StatusCreated }
+
+type invalid struct{}
+
+func (*invalid) StatusCode() int { return 42 }
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name           string
+		resp           interface{}
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Value", map[string]int{"id": 1}, http.StatusOK, `{"id":1}`},
+		{"Status code", created{ID: 1}, http.StatusCreated, `{"id":1}`},
+		{"Nil slice", []string(nil), http.StatusOK, `null`},
+		{"Nil", nil, http.StatusNoContent, ``},
+		{"Nil pointer", (*created)(nil), http.StatusNoContent, ``},
+		{"Nil pointer with status code", (*invalid)(nil), http.StatusNoContent, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body, err := Encode(tt.resp)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("status = %d, want %d", status, tt.expectedStatus)
+			}
+			if string(body) != tt.expectedBody {
+				t.Errorf("body = %s, want %s", body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	if _, _, err := Encode(&invalid{}); err == nil {
+		t.Error("Expected an error for an invalid status code")
+	}
+	if _, _, err := Encode(func() {}); err == nil {
+		t.Error("Expected an error for a value that cannot be encoded")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := Write(rec, created{ID: 1}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+	}
+	if rec.Body.String() != `{"id":1}` {
+		t.Errorf("body = %s, want %s", rec.Body.String(), `{"id":1}`)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := Write(rec, (*created)(nil)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("nil response: status = %d, body = %q, Content-Type = %q; want 204 without body",
+			rec.Code, rec.Body.String(), rec.Header().Get("Content-Type"))
+	}
+
+	rec = httptest.NewRecorder()
+	if err := Write(rec, &invalid{}); err == nil {
+		t.Error("Expected an error for an invalid status code")
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("body = %q, want nothing written on error", rec.Body.String())
+	}
+}
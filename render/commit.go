@@ -0,0 +1,18 @@
+package render
+
+// CommittedError wraps an error returned by a handler, or recovered from its panic, after the handler
+// had already started writing the response. The error could not be rendered, since the status code
+// and possibly part of the body had already been sent, so it is only reported to the error callback.
+type CommittedError struct {
+	Err error
+}
+
+// Error returns the underlying error message prefixed with the committed condition.
+func (e *CommittedError) Error() string {
+	return "error after response was committed: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *CommittedError) Unwrap() error {
+	return e.Err
+}
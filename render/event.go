@@ -0,0 +1,29 @@
+package render
+
+import (
+	"net/http"
+	"time"
+)
+
+// ErrorEvent describes an error handled by a wrapper. It is passed to the event callbacks of the wrappers,
+// so that logs and metrics can tell which endpoint failed, how and how long it took.
+type ErrorEvent struct {
+	Err         error         // Error as passed to the error callback, possibly wrapped in a ReferenceError or CommittedError
+	Request     *http.Request // Request that failed; for Fiber, a conversion of the Fiber request valid only during the callback
+	Route       string        // Route pattern that matched the request, such as "/users/{id}"
+	Status      int           // Status code of the response
+	ProblemType string        // Type URI of the problem details in the response, if any
+	Reference   string        // Reference ID sent in place of withheld error details, if any
+	Latency     time.Duration // Time from the start of the handler until the error was handled
+	Committed   bool          // Whether the response was committed before the error, so that it was not rendered
+}
+
+// IsClientError reports whether the response has a 4xx status code, meaning the client was at fault.
+func (e *ErrorEvent) IsClientError() bool {
+	return e.Status >= 400 && e.Status < 500
+}
+
+// IsServerError reports whether the response has a 5xx status code, meaning the server was at fault.
+func (e *ErrorEvent) IsServerError() bool {
+	return e.Status >= 500 && e.Status < 600
+}
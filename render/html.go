@@ -0,0 +1,154 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+// DefaultHTMLTemplate is the page used for text/html error responses when no other template matches.
+// Templates are executed with an *HTMLPage.
+var DefaultHTMLTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.Title}}</title>
+</head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+{{- if .Detail}}
+<p>{{.Detail}}</p>
+{{- end}}
+{{- if or .Type .Instance .Extensions}}
+<dl>
+{{- if .Type}}
+<dt>Type</dt><dd><a href="{{.Type}}">{{.Type}}</a></dd>
+{{- end}}
+{{- if .Instance}}
+<dt>Instance</dt><dd><code>{{.Instance}}</code></dd>
+{{- end}}
+{{- range .Extensions}}
+<dt>{{.Name}}</dt><dd><code>{{.Value}}</code></dd>
+{{- end}}
+</dl>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTMLPage is the data passed to HTML error templates.
+// All values are escaped by html/template when the page is rendered.
+type HTMLPage struct {
+	Lang       string          // Language of the page: the negotiated language, or "en" without a catalog
+	Status     int             // HTTP status code
+	StatusText string          // Standard text for the status code
+	Type       string          // Problem type URI, empty for about:blank
+	Title      string          // Problem title, or the status text if the problem has none
+	Detail     string          // Human-readable explanation specific to this occurrence
+	Instance   string          // URI reference identifying this occurrence
+	Extensions []HTMLExtension // Extension members, sorted by name
+}
+
+// HTMLExtension is an extension member of a problem shown on an HTML error page.
+type HTMLExtension struct {
+	Name  string
+	Value string // String values as is; other values encoded as JSON
+}
+
+// WithHTMLTemplate replaces DefaultHTMLTemplate as the page used for text/html error responses.
+func WithHTMLTemplate(tmpl *template.Template) Option {
+	return func(d *DefaultRenderer) {
+		d.htmlTemplate = tmpl
+	}
+}
+
+// WithStatusHTMLTemplate sets the page used for text/html error responses with the given status code.
+// Templates registered with WithTypeHTMLTemplate take precedence.
+func WithStatusHTMLTemplate(status int, tmpl *template.Template) Option {
+	return func(d *DefaultRenderer) {
+		if d.statusTemplates == nil {
+			d.statusTemplates = make(map[int]*template.Template)
+		}
+		d.statusTemplates[status] = tmpl
+	}
+}
+
+// WithTypeHTMLTemplate sets the page used for text/html error responses whose problem type is typeURI.
+func WithTypeHTMLTemplate(typeURI string, tmpl *template.Template) Option {
+	return func(d *DefaultRenderer) {
+		if d.typeTemplates == nil {
+			d.typeTemplates = make(map[string]*template.Template)
+		}
+		d.typeTemplates[typeURI] = tmpl
+	}
+}
+
+// htmlTemplateFor returns the template for a problem: by problem type first, then by status code,
+// then the renderer's default template.
+func (d *DefaultRenderer) htmlTemplateFor(status int, problem *httperror.RFC9457Error) *template.Template {
+	if tmpl, ok := d.typeTemplates[problem.Type]; ok && problem.Type != "" {
+		return tmpl
+	}
+	if tmpl, ok := d.statusTemplates[status]; ok {
+		return tmpl
+	}
+	if d.htmlTemplate != nil {
+		return d.htmlTemplate
+	}
+	return DefaultHTMLTemplate
+}
+
+// renderHTML renders a problem as an HTML page in the given language.
+func (d *DefaultRenderer) renderHTML(lang string, status int, problem *httperror.RFC9457Error) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.htmlTemplateFor(status, problem).Execute(&buf, newHTMLPage(lang, status, problem)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newHTMLPage creates the template data of a problem.
+// Pages without a negotiated language are in English, the language of the built-in messages.
+func newHTMLPage(lang string, status int, problem *httperror.RFC9457Error) *HTMLPage {
+	if lang == "" {
+		lang = "en"
+	}
+	page := &HTMLPage{
+		Lang:       lang,
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Title:      problem.Title,
+		Detail:     problem.Detail,
+		Instance:   problem.Instance,
+	}
+	if page.Title == "" {
+		page.Title = page.StatusText
+	}
+	if problem.Type != "about:blank" {
+		page.Type = problem.Type
+	}
+
+	for name, value := range problem.Extensions {
+		page.Extensions = append(page.Extensions, HTMLExtension{Name: name, Value: extensionValue(value)})
+	}
+	sort.Slice(page.Extensions, func(i, j int) bool {
+		return page.Extensions[i].Name < page.Extensions[j].Name
+	})
+	return page
+}
+
+// extensionValue formats an extension member for display.
+func extensionValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
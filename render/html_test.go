@@ -0,0 +1,139 @@
+package render
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+func renderHTMLPage(t *testing.T, renderer *DefaultRenderer, err error) string {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html")
+	resp := renderer.Render(req, err)
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("Content-Type = %s, want text/html; charset=utf-8", ct)
+	}
+	return string(resp.Body)
+}
+
+func TestDefaultRenderer_RenderHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		contains []string
+	}{
+		{
+			name:     "HttpError",
+			err:      httperror.NotFound("No such user"),
+			contains: []string{`<html lang="en">`, "<title>404 Not Found</title>", "<p>No such user</p>"},
+		},
+		{
+			name: "RFC9457Error",
+			err: httperror.NewRFC9457Error(http.StatusForbidden, "You do not have enough credit.", "Your balance is 30").
+				WithType("https://example.com/probs/out-of-credit").
+				WithInstance("/account/12345").
+				WithExtension("balance", 30),
+			contains: []string{
+				"<h1>403 You do not have enough credit.</h1>",
+				`<a href="https://example.com/probs/out-of-credit">`,
+				"<code>/account/12345</code>",
+				"<dt>balance</dt><dd><code>30</code></dd>",
+			},
+		},
+		{
+			name:     "RFC7807Error",
+			err:      httperror.BadRequestProblem7807("Missing name"),
+			contains: []string{"<h1>400 Bad Request</h1>", "<p>Missing name</p>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := renderHTMLPage(t, New(), tt.err)
+			for _, s := range tt.contains {
+				if !strings.Contains(body, s) {
+					t.Errorf("Body does not contain %q:\n%s", s, body)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_RenderHTMLEscapes(t *testing.T) {
+	err := httperror.BadRequestProblem9457(`<script>alert("detail")</script>`).
+		WithType("javascript:alert(1)").
+		WithExtension("<b>name</b>", `<img src=x onerror="alert(1)">`).
+		WithExtension("nested", map[string]interface{}{"html": "<i>"})
+
+	body := renderHTMLPage(t, New(), err)
+
+	for _, s := range []string{"<script>", "<img", "<b>", "<i>", `href="javascript:`} {
+		if strings.Contains(body, s) {
+			t.Errorf("Body contains unescaped %q:\n%s", s, body)
+		}
+	}
+	if !strings.Contains(body, "&lt;script&gt;") {
+		t.Errorf("Body does not contain the escaped detail:\n%s", body)
+	}
+}
+
+func TestDefaultRenderer_HTMLTemplates(t *testing.T) {
+	defaultTmpl := template.Must(template.New("default").Parse(`default {{.Status}}`))
+	statusTmpl := template.Must(template.New("status").Parse(`status {{.Status}} {{.Detail}}`))
+	typeTmpl := template.Must(template.New("type").Parse(`type {{.Type}}`))
+
+	renderer := New(
+		WithHTMLTemplate(defaultTmpl),
+		WithStatusHTMLTemplate(http.StatusNotFound, statusTmpl),
+		WithTypeHTMLTemplate("https://example.com/probs/out-of-credit", typeTmpl),
+	)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Default template", httperror.Conflict("taken"), "default 409"},
+		{"Status template", httperror.NotFound("<missing>"), "status 404 &lt;missing&gt;"},
+		{
+			name:     "Type template",
+			err:      httperror.ForbiddenProblem9457("No credit").WithType("https://example.com/probs/out-of-credit"),
+			expected: "type https://example.com/probs/out-of-credit",
+		},
+		{
+			name:     "Type template takes precedence over status",
+			err:      httperror.NotFoundProblem9457("No credit").WithType("https://example.com/probs/out-of-credit"),
+			expected: "type https://example.com/probs/out-of-credit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if body := renderHTMLPage(t, renderer, tt.err); body != tt.expected {
+				t.Errorf("Body = %q, want %q", body, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_HTMLTemplateError(t *testing.T) {
+	tmpl := template.Must(template.New("broken").Parse(`{{.Missing}}`))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html")
+	resp := New(WithHTMLTemplate(tmpl)).Render(req, httperror.NotFound("No such user"))
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %s, want text/plain; charset=utf-8", ct)
+	}
+	if string(resp.Body) != "No such user\n" {
+		t.Errorf("Body = %q, want %q", resp.Body, "No such user\n")
+	}
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"net/http"
+
+	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/internal/negotiate"
+)
+
+// UnexpectedMessageKey is the Catalog key of the detail sent for errors without a status code.
+// Its message receives the reference ID as its only argument, for example
+// "An unexpected error occurred. Quote reference %s when reporting this problem."
+const UnexpectedMessageKey = "httpwrap.unexpected"
+
+// WithCatalog localizes the titles and details of error responses with the given catalog.
+// The language is negotiated from the request's Accept-Language header among the catalog's languages,
+// falling back to its first language, and is sent in the Content-Language header.
+// Titles of problem types without a translation fall back to the translation of the status code
+// if they are the standard status text. Titles and details without a translation in the
+// negotiated language are sent unchanged.
+func WithCatalog(catalog *httperror.Catalog) Option {
+	return func(d *DefaultRenderer) {
+		d.catalog = catalog
+	}
+}
+
+// localize returns a copy of problem whose title and detail are translated into the language negotiated
+// with the client, along with that language. It returns problem unchanged and an empty language
+// if no catalog is configured.
+func (d *DefaultRenderer) localize(request *http.Request, status int, problem *httperror.RFC9457Error, l httperror.Localizable) (*httperror.RFC9457Error, string) {
+	if d.catalog == nil {
+		return problem, ""
+	}
+	languages := d.catalog.Languages()
+	if len(languages) == 0 {
+		return problem, ""
+	}
+
+	var acceptLanguage string
+	if request != nil {
+		acceptLanguage = request.Header.Get("Accept-Language")
+	}
+	lang := negotiate.Language(acceptLanguage, languages...)
+	if lang == "" {
+		lang = languages[0]
+	}
+
+	localized := *problem
+	if title, ok := d.catalog.Title(lang, problem.Type, status); ok {
+		localized.Title = title
+	} else if problem.Title == http.StatusText(status) {
+		// Types whose title is the status text, such as CommonProblemTypes, share the status code translation
+		if title, ok := d.catalog.Title(lang, "", status); ok {
+			localized.Title = title
+		}
+	}
+	if l != nil {
+		if key, args := l.MessageKey(); key != "" {
+			if detail, ok := d.catalog.Message(lang, key, args...); ok {
+				localized.Detail = detail
+			}
+		}
+	}
+	return &localized, lang
+}
+
+// localizable returns the error as a Localizable, or nil if it does not implement the interface.
+func localizable(sc httperror.StatusCoder) httperror.Localizable {
+	l, _ := sc.(httperror.Localizable)
+	return l
+}
@@ -0,0 +1,159 @@
+package render
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+func newTestCatalog() *httperror.Catalog {
+	return httperror.NewCatalog("en", "ko").
+		SetMessages("en", map[string]string{
+			"404":                "Not Found",
+			"user.not_found":     "User %d was not found",
+			UnexpectedMessageKey: "Something went wrong. Reference: %s",
+		}).
+		SetMessages("ko", map[string]string{
+			"404":                "찾을 수 없음",
+			"500":                "내부 서버 오류",
+			"user.not_found":     "사용자 %d을(를) 찾을 수 없습니다",
+			UnexpectedMessageKey: "예기치 않은 오류가 발생했습니다. 참조 번호: %s",
+		})
+}
+
+func TestDefaultRenderer_RenderLocalized(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		acceptLanguage   string
+		expectedLanguage string
+		expectedTitle    string
+		expectedDetail   string
+	}{
+		{
+			name:             "Korean",
+			err:              httperror.NotFoundProblem9457("User 42 not found").WithMessageKey("user.not_found", 42),
+			acceptLanguage:   "ko-KR,ko;q=0.9,en;q=0.8",
+			expectedLanguage: "ko",
+			expectedTitle:    "찾을 수 없음",
+			expectedDetail:   "사용자 42을(를) 찾을 수 없습니다",
+		},
+		{
+			name:             "English",
+			err:              httperror.NotFoundProblem7807("User 42 not found").WithMessageKey("user.not_found", 42),
+			acceptLanguage:   "en-US",
+			expectedLanguage: "en",
+			expectedTitle:    "Not Found",
+			expectedDetail:   "User 42 was not found",
+		},
+		{
+			name:             "Unsupported language falls back to the first language",
+			err:              httperror.NotFoundProblem9457("User 42 not found").WithMessageKey("user.not_found", 42),
+			acceptLanguage:   "fr",
+			expectedLanguage: "en",
+			expectedTitle:    "Not Found",
+			expectedDetail:   "User 42 was not found",
+		},
+		{
+			name:             "HttpError",
+			err:              httperror.NotFound("User 42 not found").WithMessageKey("user.not_found", 42),
+			acceptLanguage:   "ko",
+			expectedLanguage: "ko",
+			expectedTitle:    "찾을 수 없음",
+			expectedDetail:   "사용자 42을(를) 찾을 수 없습니다",
+		},
+		{
+			name:             "Missing translation keeps the original detail",
+			err:              httperror.NotFoundProblem9457("User 42 not found").WithMessageKey("user.missing"),
+			acceptLanguage:   "ko",
+			expectedLanguage: "ko",
+			expectedTitle:    "찾을 수 없음",
+			expectedDetail:   "User 42 not found",
+		},
+		{
+			name: "Custom type title",
+			err: httperror.NewRFC9457ErrorWithType(http.StatusForbidden, "https://example.com/probs/out-of-credit",
+				"You do not have enough credit.", "Balance too low"),
+			acceptLanguage:   "ko",
+			expectedLanguage: "ko",
+			expectedTitle:    "You do not have enough credit.",
+			expectedDetail:   "Balance too low",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", "application/problem+json")
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			resp := New(WithCatalog(newTestCatalog())).Render(req, tt.err)
+
+			if lang := resp.Header.Get("Content-Language"); lang != tt.expectedLanguage {
+				t.Errorf("Content-Language = %q, want %q", lang, tt.expectedLanguage)
+			}
+			if vary := resp.Header.Values("Vary"); strings.Join(vary, ", ") != "Accept, Accept-Language" {
+				t.Errorf("Vary = %q, want [Accept Accept-Language]", vary)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(resp.Body, &body); err != nil {
+				t.Fatalf("Failed to unmarshal body: %v", err)
+			}
+			if body["title"] != tt.expectedTitle {
+				t.Errorf("title = %v, want %q", body["title"], tt.expectedTitle)
+			}
+			if body["detail"] != tt.expectedDetail {
+				t.Errorf("detail = %v, want %q", body["detail"], tt.expectedDetail)
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_RenderLocalizedDoesNotModifyError(t *testing.T) {
+	err := httperror.NotFoundProblem9457("User 42 not found").WithMessageKey("user.not_found", 42)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "ko")
+	New(WithCatalog(newTestCatalog())).Render(req, err)
+
+	if err.Title != "Not Found" || err.Detail != "User 42 not found" {
+		t.Errorf("Render modified the error: %+v", err)
+	}
+}
+
+func TestDefaultRenderer_RenderLocalizedHTML(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Accept-Language", "ko")
+	resp := New(WithCatalog(newTestCatalog())).Render(req, httperror.NotFoundProblem9457("User 42 not found"))
+
+	if body := string(resp.Body); !strings.Contains(body, `<html lang="ko">`) || !strings.Contains(body, "<h1>404 찾을 수 없음</h1>") {
+		t.Errorf("Body is not a Korean page:\n%s", body)
+	}
+}
+
+func TestDefaultRenderer_RenderLocalizedUnexpected(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/plain")
+	req.Header.Set("Accept-Language", "ko")
+	resp := New(WithCatalog(newTestCatalog())).Render(req, http.ErrHandlerTimeout)
+
+	expected := "예기치 않은 오류가 발생했습니다. 참조 번호: " + resp.Reference + "\n"
+	if string(resp.Body) != expected {
+		t.Errorf("Body = %q, want %q", resp.Body, expected)
+	}
+}
+
+func TestDefaultRenderer_RenderWithoutCatalog(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "ko")
+	resp := New().Render(req, httperror.NotFound("Not found"))
+
+	if lang := resp.Header.Get("Content-Language"); lang != "" {
+		t.Errorf("Content-Language = %q, want none", lang)
+	}
+}
@@ -0,0 +1,132 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/http"
+
+	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/internal/negotiate"
+)
+
+// Media types produced by the DefaultRenderer.
+const (
+	MediaTypeProblemJSON = "application/problem+json"
+	MediaTypeProblemXML  = "application/problem+xml"
+	MediaTypeHTML        = "text/html"
+	MediaTypeText        = "text/plain"
+)
+
+// mediaTypes lists the media types produced by the DefaultRenderer in their default order of preference.
+var mediaTypes = []string{MediaTypeProblemJSON, MediaTypeProblemXML, MediaTypeHTML, MediaTypeText}
+
+// aliases maps generic media types to the problem format served in their place,
+// so that clients asking for plain JSON or XML still receive a problem document.
+var aliases = map[string]string{
+	"application/json": MediaTypeProblemJSON,
+	"application/xml":  MediaTypeProblemXML,
+	"text/xml":         MediaTypeProblemXML,
+}
+
+// Negotiate selects the media type of an error response from the request's Accept header.
+// The result is one of MediaTypeProblemJSON, MediaTypeProblemXML, MediaTypeHTML and MediaTypeText.
+// The preferred media type wins ties and is used when the request has no Accept header;
+// application/json and application/xml are served as their problem equivalents.
+// If the client accepts none of them, the preferred media type is used anyway, since
+// an error response is more useful to the client than a 406 Not Acceptable.
+func Negotiate(request *http.Request, preferred string) string {
+	preferred = mediaType(preferred)
+
+	offers := make([]string, 0, len(mediaTypes)+len(aliases))
+	offers = append(offers, preferred)
+	for _, mt := range mediaTypes {
+		if mt != preferred {
+			offers = append(offers, mt)
+		}
+	}
+	offers = append(offers, "application/json", "application/xml", "text/xml")
+
+	var accept string
+	if request != nil {
+		accept = request.Header.Get("Accept")
+	}
+
+	chosen := negotiate.ContentType(accept, offers...)
+	if alias, ok := aliases[chosen]; ok {
+		chosen = alias
+	}
+	if chosen == "" {
+		chosen = preferred
+	}
+	return chosen
+}
+
+// mediaType maps a Content-Type value to the DefaultRenderer media type it corresponds to,
+// defaulting to MediaTypeProblemJSON for unknown types.
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return MediaTypeProblemJSON
+	}
+	if alias, ok := aliases[mt]; ok {
+		return alias
+	}
+	for _, known := range mediaTypes {
+		if mt == known {
+			return known
+		}
+	}
+	return MediaTypeProblemJSON
+}
+
+// encode serializes a problem in the given media type and language.
+// Problems that have no XML representation are serialized as JSON instead,
+// and if the problem cannot be serialized at all, it falls back to plain text.
+func (d *DefaultRenderer) encode(mediaType, lang string, status int, problem *httperror.RFC9457Error) *Response {
+	switch mediaType {
+	case MediaTypeProblemJSON:
+		body, err := json.Marshal(problem)
+		if err != nil {
+			break
+		}
+		return &Response{
+			Status: status,
+			Header: http.Header{"Content-Type": {MediaTypeProblemJSON}},
+			Body:   body,
+		}
+	case MediaTypeProblemXML:
+		body, err := xml.Marshal(problem)
+		if err != nil {
+			return d.encode(MediaTypeProblemJSON, lang, status, problem)
+		}
+		return &Response{
+			Status: status,
+			Header: http.Header{"Content-Type": {MediaTypeProblemXML}},
+			Body:   append([]byte(xml.Header), body...),
+		}
+	case MediaTypeHTML:
+		body, err := d.renderHTML(lang, status, problem)
+		if err != nil {
+			break
+		}
+		return &Response{
+			Status: status,
+			Header: http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			Body:   body,
+		}
+	}
+	return d.text(status, textMessage(status, problem))
+}
+
+// textMessage returns the plain text representation of a problem: its detail, or its title if there is none.
+func textMessage(status int, problem *httperror.RFC9457Error) string {
+	switch {
+	case problem.Detail != "":
+		return problem.Detail
+	case problem.Title != "":
+		return problem.Title
+	default:
+		return http.StatusText(status)
+	}
+}
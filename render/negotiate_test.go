@@ -0,0 +1,198 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name      string
+		accept    string
+		preferred string
+		expected  string
+	}{
+		{"No Accept header", "", MediaTypeProblemJSON, MediaTypeProblemJSON},
+		{"No Accept header prefers text", "", MediaTypeText, MediaTypeText},
+		{"Wildcard", "*/*", MediaTypeText, MediaTypeText},
+		{"Problem XML", "application/problem+xml", MediaTypeProblemJSON, MediaTypeProblemXML},
+		{"Generic JSON", "application/json", MediaTypeText, MediaTypeProblemJSON},
+		{"Generic XML", "application/xml", MediaTypeProblemJSON, MediaTypeProblemXML},
+		{"Legacy XML", "text/xml", MediaTypeProblemJSON, MediaTypeProblemXML},
+		{"Browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", MediaTypeProblemJSON, MediaTypeHTML},
+		{"Quality values", "application/problem+json;q=0.5, text/plain", MediaTypeProblemJSON, MediaTypeText},
+		{"Type wildcard", "text/*", MediaTypeProblemJSON, MediaTypeHTML},
+		{"Type wildcard keeps preferred", "text/*", MediaTypeText, MediaTypeText},
+		{"Nothing acceptable", "image/png", MediaTypeProblemXML, MediaTypeProblemXML},
+		{"Preferred from content type", "", "application/problem+xml; charset=utf-8", MediaTypeProblemXML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if got := Negotiate(req, tt.preferred); got != tt.expected {
+				t.Errorf("Negotiate(%q, %q) = %q, want %q", tt.accept, tt.preferred, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_RenderNegotiated(t *testing.T) {
+	tests := []struct {
+		name                string
+		err                 error
+		accept              string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Problem as XML",
+			err:                 httperror.NotFoundProblem9457("User 123 not found"),
+			accept:              "application/problem+xml",
+			expectedContentType: "application/problem+xml",
+			expectedBody:        "<detail>User 123 not found</detail>",
+		},
+		{
+			name:                "Problem as text",
+			err:                 httperror.NotFoundProblem7807("User 123 not found"),
+			accept:              "text/plain",
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "User 123 not found\n",
+		},
+		{
+			name:                "Problem as HTML",
+			err:                 httperror.NotFoundProblem9457("<script>"),
+			accept:              "text/html",
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<p>&lt;script&gt;</p>",
+		},
+		{
+			name:                "HttpError from problem as XML",
+			err:                 httperror.BadRequestProblem9457("Invalid input").ToHttpError(),
+			accept:              "application/xml",
+			expectedContentType: "application/problem+xml",
+			expectedBody:        "<detail>Invalid input</detail>",
+		},
+		{
+			name:                "XML HttpError without Accept header",
+			err:                 httperror.BadRequestProblem9457("Invalid input").ToXMLHttpError(),
+			expectedContentType: "application/problem+xml",
+			expectedBody:        "<detail>Invalid input</detail>",
+		},
+		{
+			name:                "HttpError as JSON",
+			err:                 httperror.NotFound("Not found"),
+			accept:              "application/json",
+			expectedContentType: "application/problem+json",
+			expectedBody:        `"detail":"Not found"`,
+		},
+		{
+			name:                "HttpError with content type ignores Accept",
+			err:                 httperror.New(400, `{"error":"bad request"}`, "application/json"),
+			accept:              "application/xml",
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"bad request"}`,
+		},
+		{
+			name:                "Unknown error as text",
+			err:                 http.ErrHandlerTimeout,
+			accept:              "text/plain",
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Quote reference ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp := New().Render(req, tt.err)
+
+			if ct := resp.Header.Get("Content-Type"); ct != tt.expectedContentType {
+				t.Errorf("Content-Type = %s, want %s", ct, tt.expectedContentType)
+			}
+			if !strings.Contains(string(resp.Body), tt.expectedBody) {
+				t.Errorf("Body = %q, want it to contain %q", resp.Body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_RenderNegotiatedRoundTrip(t *testing.T) {
+	problem := httperror.NewRFC9457Error(http.StatusForbidden, "Forbidden", "Not enough credit").
+		WithType("https://example.com/probs/out-of-credit").
+		WithExtension("balance", 30)
+
+	for _, accept := range []string{"application/problem+json", "application/problem+xml"} {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", accept)
+			resp := New().Render(req, problem)
+
+			if resp.Header.Get("Vary") != "Accept" {
+				t.Errorf("Vary = %q, want Accept", resp.Header.Get("Vary"))
+			}
+
+			var decoded httperror.RFC9457Error
+			var err error
+			if accept == "application/problem+json" {
+				err = json.Unmarshal(resp.Body, &decoded)
+			} else {
+				err = xml.Unmarshal(resp.Body, &decoded)
+			}
+			if err != nil {
+				t.Fatalf("Failed to decode body: %v", err)
+			}
+			if decoded.Type != problem.Type || decoded.Status != problem.Status || decoded.Detail != problem.Detail {
+				t.Errorf("Decoded problem = %+v, want %+v", decoded, problem)
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_RenderXMLFallback(t *testing.T) {
+	problem := httperror.BadRequestProblem9457("Invalid input").WithExtension("not a name", "value")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/problem+xml")
+	resp := New().Render(req, problem)
+
+	if ct := resp.Header.Get("Content-Type"); ct != MediaTypeProblemJSON {
+		t.Fatalf("Content-Type = %q, want %q", ct, MediaTypeProblemJSON)
+	}
+	var decoded httperror.RFC9457Error
+	if err := json.Unmarshal(resp.Body, &decoded); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if decoded.Extensions["not a name"] != "value" {
+		t.Errorf("Extensions = %v, want the extension kept", decoded.Extensions)
+	}
+}
+
+func TestWrite_MergesVary(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Vary", "Origin, accept")
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	Write(w, &Response{
+		Status: http.StatusNotFound,
+		Header: http.Header{"Vary": {"Accept", "Accept-Language"}},
+	})
+
+	expected := []string{"Origin, accept", "Accept-Encoding", "Accept-Language"}
+	got := w.Header().Values("Vary")
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Vary = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,41 @@
+package render
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// ReferenceError wraps an error whose details were withheld from the client.
+// Reference is the opaque ID that was sent to the client instead, so the
+// error reported to the callback can be correlated with the client's report.
+type ReferenceError struct {
+	Reference string
+	Err       error
+}
+
+// Error returns the reference ID followed by the underlying error message.
+func (e *ReferenceError) Error() string {
+	return "reference " + e.Reference + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// Report returns the error that should be passed to an error callback after resp was rendered for err.
+// If the Response carries a reference ID, err is wrapped in a ReferenceError; otherwise err is returned as is.
+func Report(err error, resp *Response) error {
+	if resp == nil || resp.Reference == "" {
+		return err
+	}
+	return &ReferenceError{Reference: resp.Reference, Err: err}
+}
+
+// newReference generates a random, opaque reference ID.
+func newReference() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read never returns an error
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,250 @@
+// Package render converts errors returned by handlers into HTTP error responses.
+// It is shared by the httpwrap, chiwrap and fiberwrap wrappers so that a single
+// Renderer controls the status code, headers and body of every error response,
+// regardless of the router in use.
+package render
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+// Response describes an error response independently of the router that writes it.
+type Response struct {
+	Status      int         // HTTP status code
+	Header      http.Header // Headers to set on the response
+	Body        []byte      // Response body
+	Reference   string      // Reference ID sent in place of withheld error details, if any
+	ProblemType string      // Type URI of the problem details in the body, if any
+}
+
+// Renderer converts an error returned by a handler into a Response.
+// The request is provided so that implementations can inspect headers such as Accept.
+type Renderer interface {
+	Render(request *http.Request, err error) *Response
+}
+
+// RendererFunc is an adapter that allows ordinary functions to be used as Renderers.
+type RendererFunc func(request *http.Request, err error) *Response
+
+// Render calls f(request, err).
+func (f RendererFunc) Render(request *http.Request, err error) *Response {
+	return f(request, err)
+}
+
+// DefaultRenderer is the Renderer used by the wrappers when no custom Renderer is configured.
+// It looks for the first error in the chain implementing httperror.StatusCoder.
+//
+// Errors implementing httperror.ProblemDetailer, such as RFC7807Error, RFC9457Error and HttpErrors created
+// by their ToHttpError methods, are rendered in the format negotiated from the request's Accept header:
+// application/problem+json, application/problem+xml, text/html or text/plain. HTML pages are rendered
+// with DefaultHTMLTemplate unless other templates are configured with WithHTMLTemplate,
+// WithStatusHTMLTemplate or WithTypeHTMLTemplate. HttpErrors and other StatusCoders carrying
+// only a message are negotiated the same way, preferring text/plain.
+// An HttpError with an explicit ContentType but no problem details is written as is, and any other
+// httperror.ProblemRenderer writes its own body.
+//
+// Errors without a status code, and panics recovered as an httperror.PanicError even if the panic value
+// carries one, result in a 500 Internal Server Error whose details are withheld from the client,
+// unless development mode is enabled.
+type DefaultRenderer struct {
+	development     bool
+	htmlTemplate    *template.Template
+	statusTemplates map[int]*template.Template
+	typeTemplates   map[string]*template.Template
+	catalog         *httperror.Catalog
+	bareText        bool
+}
+
+// Option configures a DefaultRenderer.
+type Option func(*DefaultRenderer)
+
+// WithDevelopment enables development mode, in which errors without a status code are written
+// to the client verbatim using err.Error(). This exposes internal details such as SQL messages
+// and file paths, so it must not be used in production.
+func WithDevelopment() Option {
+	return func(d *DefaultRenderer) {
+		d.development = true
+	}
+}
+
+// WithBareText writes plain text bodies without the trailing newline appended by http.Error,
+// matching the output of Fiber's Ctx.SendString.
+func WithBareText() Option {
+	return func(d *DefaultRenderer) {
+		d.bareText = true
+	}
+}
+
+// New creates a new DefaultRenderer with the given options.
+func New(opts ...Option) *DefaultRenderer {
+	d := &DefaultRenderer{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Render implements the Renderer interface.
+func (d *DefaultRenderer) Render(request *http.Request, err error) *Response {
+	// A panic is unexpected even if its value carries a status code
+	var pe *httperror.PanicError
+	if errors.As(err, &pe) {
+		return d.unexpected(request, err)
+	}
+
+	var sc httperror.StatusCoder
+	if !errors.As(err, &sc) {
+		return d.unexpected(request, err)
+	}
+
+	status := sc.StatusCode()
+	if !validStatus(status) {
+		return d.unexpected(request, err)
+	}
+
+	if pd, ok := sc.(httperror.ProblemDetailer); ok {
+		if problem := pd.ProblemDetails(); problem != nil {
+			preferred := MediaTypeProblemJSON
+			if he, ok := sc.(*httperror.HttpError); ok {
+				preferred = mediaType(he.ContentType)
+			}
+			return d.renderProblem(request, status, problem, preferred, localizable(sc))
+		}
+	}
+
+	switch e := sc.(type) {
+	case *httperror.HttpError:
+		// Use Content-Type if specified in HttpError
+		if e.ContentType != "" {
+			return &Response{
+				Status: status,
+				Header: http.Header{"Content-Type": {e.ContentType}},
+				Body:   []byte(e.Message),
+			}
+		}
+		return d.renderProblem(request, status, messageProblem(status, e.Message), MediaTypeText, e)
+	case httperror.ProblemRenderer:
+		contentType, body, renderErr := e.RenderProblem()
+		if renderErr != nil {
+			// If the error cannot render itself, fall back to its plain text message
+			return d.text(status, message(sc))
+		}
+		return &Response{
+			Status: status,
+			Header: http.Header{"Content-Type": {contentType}},
+			Body:   body,
+		}
+	default:
+		return d.renderProblem(request, status, messageProblem(status, message(sc)), MediaTypeText, localizable(sc))
+	}
+}
+
+// unexpected renders an error that does not carry a status code.
+// In development mode the error is written verbatim; otherwise a generic problem
+// with an opaque reference ID is written, so internal details never reach the client.
+func (d *DefaultRenderer) unexpected(request *http.Request, err error) *Response {
+	if d.development {
+		return d.text(http.StatusInternalServerError, err.Error())
+	}
+
+	reference := newReference()
+	problem := httperror.InternalServerErrorProblem9457("The server encountered an unexpected condition. Quote reference "+reference+" when reporting this problem.").
+		WithExtension("reference", reference).
+		WithMessageKey(UnexpectedMessageKey, reference)
+	resp := d.renderProblem(request, http.StatusInternalServerError, problem, MediaTypeProblemJSON, problem)
+	resp.Reference = reference
+	return resp
+}
+
+// renderProblem renders a problem in the format and language negotiated with the client.
+// The message of l, if any, replaces the detail of the problem when a catalog is configured.
+func (d *DefaultRenderer) renderProblem(request *http.Request, status int, problem *httperror.RFC9457Error, preferred string, l httperror.Localizable) *Response {
+	problem, lang := d.localize(request, status, problem, l)
+	resp := d.encode(Negotiate(request, preferred), lang, status, problem)
+	resp.ProblemType = problem.Type
+	resp.Header.Set("Vary", "Accept")
+	if lang != "" {
+		resp.Header.Set("Content-Language", lang)
+		resp.Header.Add("Vary", "Accept-Language")
+	}
+	return resp
+}
+
+// messageProblem creates the problem details of an error that only carries a status code and a message.
+func messageProblem(status int, message string) *httperror.RFC9457Error {
+	return httperror.NewRFC9457Error(status, http.StatusText(status), message)
+}
+
+// message returns the client-facing message of an error carrying a status code.
+// If the error does not implement httperror.ErrorMessager, the status text is used instead.
+func message(sc httperror.StatusCoder) string {
+	if em, ok := sc.(httperror.ErrorMessager); ok {
+		return em.ErrorMessage()
+	}
+	return http.StatusText(sc.StatusCode())
+}
+
+// validStatus reports whether status can be passed to http.ResponseWriter.WriteHeader.
+func validStatus(status int) bool {
+	return status >= 100 && status <= 999
+}
+
+// text creates a plain text Response, without the trailing newline if bare text is enabled.
+func (d *DefaultRenderer) text(status int, message string) *Response {
+	resp := Text(status, message)
+	if d.bareText {
+		resp.Body = resp.Body[:len(resp.Body)-1]
+	}
+	return resp
+}
+
+// Text creates a plain text Response in the same format as http.Error.
+func Text(status int, message string) *Response {
+	return &Response{
+		Status: status,
+		Header: http.Header{
+			"Content-Type":           {"text/plain; charset=utf-8"},
+			"X-Content-Type-Options": {"nosniff"},
+		},
+		Body: []byte(message + "\n"),
+	}
+}
+
+// Write writes the Response to the given http.ResponseWriter.
+// Headers from the Response replace any headers with the same name already set on the writer,
+// except Vary, whose values are added to those already present.
+func Write(writer http.ResponseWriter, resp *Response) {
+	h := writer.Header()
+	// The body is replaced, so any previously declared length no longer applies
+	h.Del("Content-Length")
+	for key, values := range resp.Header {
+		if key == "Vary" {
+			for _, value := range values {
+				if !hasToken(h.Values(key), value) {
+					h.Add(key, value)
+				}
+			}
+			continue
+		}
+		h[key] = values
+	}
+	writer.WriteHeader(resp.Status)
+	writer.Write(resp.Body)
+}
+
+// hasToken reports whether any of the comma-separated header values contains token, ignoring case.
+func hasToken(values []string, token string) bool {
+	for _, value := range values {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,292 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+type domainError struct {
+	status int
+}
+
+func (e *domainError) Error() string   { return "domain error" }
+func (e *domainError) StatusCode() int { return e.status }
+
+type messageError struct {
+	domainError
+}
+
+func (e *messageError) ErrorMessage() string { return "public message" }
+
+func TestDefaultRenderer_Render(t *testing.T) {
+	tests := []struct {
+		name                string
+		err                 error
+		opts                []Option
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "HttpError with content type",
+			err:                 httperror.New(400, `{"error":"bad request"}`, "application/json"),
+			expectedStatus:      400,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"bad request"}`,
+		},
+		{
+			name:                "HttpError without content type",
+			err:                 httperror.NotFound("Not found"),
+			expectedStatus:      404,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Not found\n",
+		},
+		{
+			name:                "Wrapped HttpError",
+			err:                 fmt.Errorf("loading user: %w", httperror.Forbidden("Access denied")),
+			expectedStatus:      403,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Access denied\n",
+		},
+		{
+			name:                "StatusCoder without message",
+			err:                 &domainError{status: http.StatusConflict},
+			expectedStatus:      409,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Conflict\n",
+		},
+		{
+			name:                "StatusCoder with message",
+			err:                 &messageError{domainError{status: http.StatusGone}},
+			expectedStatus:      410,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "public message\n",
+		},
+		{
+			name:                "StatusCoder with invalid status in development mode",
+			err:                 &domainError{status: 0},
+			opts:                []Option{WithDevelopment()},
+			expectedStatus:      500,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "domain error\n",
+		},
+		{
+			name:                "Unknown error in development mode",
+			err:                 errors.New("boom"),
+			opts:                []Option{WithDevelopment()},
+			expectedStatus:      500,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "boom\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			resp := New(tt.opts...).Render(req, tt.err)
+
+			if resp.Status != tt.expectedStatus {
+				t.Errorf("Status = %d, want %d", resp.Status, tt.expectedStatus)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != tt.expectedContentType {
+				t.Errorf("Content-Type = %s, want %s", ct, tt.expectedContentType)
+			}
+			if string(resp.Body) != tt.expectedBody {
+				t.Errorf("Body = %q, want %q", resp.Body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_RenderUnexpected(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "Unknown error",
+			err:  errors.New("pq: relation \"users\" does not exist"),
+		},
+		{
+			name: "StatusCoder with invalid status",
+			err:  &domainError{status: 0},
+		},
+		{
+			name: "Panic with a StatusCoder",
+			err:  httperror.NewPanicError(httperror.Forbidden("secret internal detail")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := New().Render(httptest.NewRequest("GET", "/", nil), tt.err)
+
+			if resp.Status != http.StatusInternalServerError {
+				t.Errorf("Status = %d, want %d", resp.Status, http.StatusInternalServerError)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %s, want application/problem+json", ct)
+			}
+			if resp.Reference == "" {
+				t.Fatal("Expected a reference ID")
+			}
+			if strings.Contains(string(resp.Body), tt.err.Error()) {
+				t.Errorf("Body leaks the error message: %s", resp.Body)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(resp.Body, &body); err != nil {
+				t.Fatalf("Failed to unmarshal body: %v", err)
+			}
+			if body["reference"] != resp.Reference {
+				t.Errorf("reference = %v, want %s", body["reference"], resp.Reference)
+			}
+
+			reported := Report(tt.err, resp)
+			if !errors.Is(reported, tt.err) {
+				t.Errorf("Report() should wrap the original error, got %v", reported)
+			}
+			if !strings.Contains(reported.Error(), resp.Reference) {
+				t.Errorf("Report() should include the reference, got %v", reported)
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_BareText(t *testing.T) {
+	resp := New(WithBareText()).Render(httptest.NewRequest("GET", "/", nil), httperror.NotFound("Not found"))
+
+	if resp.Status != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", resp.Status, http.StatusNotFound)
+	}
+	if string(resp.Body) != "Not found" {
+		t.Errorf("Body = %q, want %q", resp.Body, "Not found")
+	}
+}
+
+func TestReport_WithoutReference(t *testing.T) {
+	err := httperror.BadRequest("invalid")
+	resp := New().Render(httptest.NewRequest("GET", "/", nil), err)
+
+	if reported := Report(err, resp); reported != err {
+		t.Errorf("Report() = %v, want %v", reported, err)
+	}
+}
+
+func TestDefaultRenderer_RenderProblem(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "RFC9457Error",
+			err:  httperror.NotFoundProblem9457("User 123 not found"),
+		},
+		{
+			name: "RFC7807Error",
+			err:  httperror.NotFoundProblem7807("User 123 not found"),
+		},
+		{
+			name: "Wrapped RFC9457Error",
+			err:  fmt.Errorf("handler: %w", httperror.NotFoundProblem9457("User 123 not found")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := New().Render(httptest.NewRequest("GET", "/users/123", nil), tt.err)
+
+			if resp.Status != http.StatusNotFound {
+				t.Errorf("Status = %d, want %d", resp.Status, http.StatusNotFound)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %s, want application/problem+json", ct)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(resp.Body, &body); err != nil {
+				t.Fatalf("Failed to unmarshal body: %v", err)
+			}
+			if body["detail"] != "User 123 not found" {
+				t.Errorf("detail = %v, want %q", body["detail"], "User 123 not found")
+			}
+			if resp.ProblemType != body["type"] {
+				t.Errorf("ProblemType = %q, want %v", resp.ProblemType, body["type"])
+			}
+		})
+	}
+}
+
+func TestDefaultRenderer_RenderCustomTitle(t *testing.T) {
+	err := httperror.NewRFC9457ErrorWithType(http.StatusNotFound, "https://example.com/probs/no-user", "User Not Found", "User with ID 123 not found")
+	resp := New().Render(httptest.NewRequest("GET", "/", nil), err)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("Failed to unmarshal body: %v", err)
+	}
+	if body["title"] != "User Not Found" {
+		t.Errorf("title = %v, want %q", body["title"], "User Not Found")
+	}
+}
+
+func TestErrorEvent_Class(t *testing.T) {
+	tests := []struct {
+		status      int
+		clientError bool
+		serverError bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusNotFound, true, false},
+		{http.StatusInternalServerError, false, true},
+	}
+
+	for _, tt := range tests {
+		event := &ErrorEvent{Status: tt.status}
+		if event.IsClientError() != tt.clientError || event.IsServerError() != tt.serverError {
+			t.Errorf("Status %d: IsClientError() = %v, IsServerError() = %v", tt.status, event.IsClientError(), event.IsServerError())
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Length", "42")
+
+	Write(w, &Response{
+		Status: http.StatusTeapot,
+		Header: http.Header{"Content-Type": {"text/plain"}},
+		Body:   []byte("short and stout"),
+	})
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected status %d, got %d", http.StatusTeapot, w.Code)
+	}
+	if w.Header().Get("Content-Length") != "" {
+		t.Errorf("Expected Content-Length to be removed, got %s", w.Header().Get("Content-Length"))
+	}
+	if w.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("Expected content type text/plain, got %s", w.Header().Get("Content-Type"))
+	}
+	if w.Body.String() != "short and stout" {
+		t.Errorf("Expected body 'short and stout', got %s", w.Body.String())
+	}
+}
+
+func TestRendererFunc(t *testing.T) {
+	var renderer Renderer = RendererFunc(func(request *http.Request, err error) *Response {
+		return Text(http.StatusServiceUnavailable, "maintenance")
+	})
+
+	resp := renderer.Render(httptest.NewRequest("GET", "/", nil), errors.New("ignored"))
+	if resp.Status != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", resp.Status, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,214 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// rule is a single parsed rule of a validate tag.
+type rule struct {
+	name string
+	arg  string
+}
+
+// patterns caches compiled pattern rules, since the same tags are validated on every request.
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// parseRules parses a validate tag into its rules.
+func parseRules(tag string) ([]rule, error) {
+	var rules []rule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "pattern=") {
+			// The pattern extends to the end of the tag, since it may contain commas
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "required", "omitempty", "email":
+			if hasArg {
+				return nil, fmt.Errorf("rule %q takes no argument", name)
+			}
+		case "min", "max", "len", "oneof", "pattern":
+			if !hasArg || arg == "" {
+				return nil, fmt.Errorf("rule %q requires an argument", name)
+			}
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		rules = append(rules, rule{name: name, arg: arg})
+	}
+	return rules, nil
+}
+
+// check applies the rule to rv. It returns a description of the failure, or "" if rv satisfies the rule.
+// An error is returned if the rule cannot be applied to the kind of rv or its argument is malformed.
+func (r rule) check(rv reflect.Value) (string, error) {
+	switch r.name {
+	case "min", "max":
+		return r.checkBound(rv)
+	case "len":
+		n, err := strconv.Atoi(r.arg)
+		if err != nil {
+			return "", err
+		}
+		length, unit, ok := size(rv)
+		if !ok {
+			return "", unsupported(rv)
+		}
+		if length != n {
+			return fmt.Sprintf("must be exactly %d %s long", n, plural(n, unit)), nil
+		}
+	case "oneof":
+		value, ok := scalar(rv)
+		if !ok {
+			return "", unsupported(rv)
+		}
+		options := strings.Fields(r.arg)
+		for _, option := range options {
+			if value == option {
+				return "", nil
+			}
+		}
+		return "must be one of: " + strings.Join(options, ", "), nil
+	case "pattern":
+		if rv.Kind() != reflect.String {
+			return "", unsupported(rv)
+		}
+		re, err := compile(r.arg)
+		if err != nil {
+			return "", err
+		}
+		if !re.MatchString(rv.String()) {
+			return "must match the pattern " + r.arg, nil
+		}
+	case "email":
+		if rv.Kind() != reflect.String {
+			return "", unsupported(rv)
+		}
+		addr, err := mail.ParseAddress(rv.String())
+		if err != nil || addr.Address != rv.String() {
+			return "must be a valid email address", nil
+		}
+	}
+	return "", nil
+}
+
+// checkBound applies a min or max rule to rv.
+func (r rule) checkBound(rv reflect.Value) (string, error) {
+	isMin := r.name == "min"
+	verb := "at most"
+	if isMin {
+		verb = "at least"
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bound, err := strconv.ParseInt(r.arg, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		if v := rv.Int(); (isMin && v < bound) || (!isMin && v > bound) {
+			return fmt.Sprintf("must be %s %d", verb, bound), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bound, err := strconv.ParseUint(r.arg, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		if v := rv.Uint(); (isMin && v < bound) || (!isMin && v > bound) {
+			return fmt.Sprintf("must be %s %d", verb, bound), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		bound, err := strconv.ParseFloat(r.arg, 64)
+		if err != nil {
+			return "", err
+		}
+		if v := rv.Float(); (isMin && v < bound) || (!isMin && v > bound) {
+			return fmt.Sprintf("must be %s %s", verb, r.arg), nil
+		}
+	default:
+		bound, err := strconv.Atoi(r.arg)
+		if err != nil {
+			return "", err
+		}
+		length, unit, ok := size(rv)
+		if !ok {
+			return "", unsupported(rv)
+		}
+		if (isMin && length < bound) || (!isMin && length > bound) {
+			return fmt.Sprintf("must be %s %d %s long", verb, bound, plural(bound, unit)), nil
+		}
+	}
+	return "", nil
+}
+
+// size returns the length of a string in characters or of a collection in elements, along with the unit.
+func size(rv reflect.Value) (int, string, bool) {
+	switch rv.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(rv.String()), "character", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), "item", true
+	}
+	return 0, "", false
+}
+
+// scalar formats strings, booleans and numbers for comparison with oneof options.
+func scalar(rv reflect.Value) (string, bool) {
+	switch rv.Kind() {
+	// Values are formatted without rv.Interface, which panics for fields promoted from unexported embedded structs
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), true
+	}
+	return "", false
+}
+
+// compile returns the compiled regular expression of a pattern rule.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// plural returns unit with an "s" appended unless n is 1.
+func plural(n int, unit string) string {
+	if n == 1 {
+		return unit
+	}
+	return unit + "s"
+}
+
+// unsupported returns the error for a rule applied to a value of the wrong kind.
+func unsupported(rv reflect.Value) error {
+	if !rv.IsValid() {
+		return errors.New("not supported for nil values")
+	}
+	return fmt.Errorf("not supported for %s values", rv.Kind())
+}
@@ -0,0 +1,253 @@
+// Package validate checks decoded request values against rules declared in struct tags
+// and reports every failing field as an RFC9457 validation problem.
+//
+// Rules are listed in the "validate" tag, separated by commas:
+//
+//	type CreateUser struct {
+//		Name  string   `json:"name" validate:"required,max=64"`
+//		Email string   `json:"email" validate:"required,email"`
+//		Age   int      `json:"age" validate:"min=0,max=150"`
+//		Role  string   `json:"role" validate:"omitempty,oneof=admin member"`
+//		Code  string   `json:"code" validate:"len=6,pattern=^[0-9A-Z]+$"`
+//		Tags  []string `json:"tags" validate:"max=10"`
+//	}
+//
+// The supported rules are:
+//
+//   - required: the value must not be the zero value; nil pointers, empty strings, slices and maps fail.
+//   - omitempty: the remaining rules are skipped if the value is the zero value.
+//   - min=N, max=N: numbers must be at least or at most N; strings, slices, arrays and maps
+//     must have at least or at most N characters or elements.
+//   - len=N: strings, slices, arrays and maps must have exactly N characters or elements.
+//   - oneof=A B C: the value must be one of the space-separated values.
+//   - pattern=RE: strings must match the regular expression RE, which is not implicitly anchored.
+//     Since RE may contain commas, pattern must be the last rule of the tag.
+//   - email: strings must be a bare email address such as "gopher@example.com".
+//
+// Rules of nil pointers other than required are skipped. Nested structs, and structs in slices,
+// arrays and maps, are validated recursively. Failures are located with JSON Pointers built from the
+// fields' json tag names, so they refer to the request body the value was decoded from, and map entries
+// are validated in the order of their sorted keys. Fields tagged with `query` or `path`, as decoded by
+// decode.Params, are located by parameter name instead. Other fields tagged `json:"-"` are not validated.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+// Struct validates v, which must be a struct or a pointer to a struct, against its "validate" struct tags.
+// It returns nil if every rule is satisfied and a *httperror.ValidationErrors listing every failing field
+// otherwise, which handlers can return directly to respond with a 400 Bad Request problem.
+// Invalid tags, such as unknown rules or malformed arguments, are programming errors and are reported
+// as a plain error instead, which renderers treat as an internal server error.
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return errors.New("validate: nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %T is not a struct", v)
+	}
+
+	errs := httperror.NewValidationErrors()
+	if err := validateStruct(errs, rv, nil); err != nil {
+		return err
+	}
+	return errs.Err()
+}
+
+// validateStruct validates the fields of the struct rv located at path.
+func validateStruct(errs *httperror.ValidationErrors, rv reflect.Value, path []string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, embedded := fieldName(field)
+		if !field.IsExported() && !embedded {
+			// Exported fields of unexported embedded structs are still promoted, as in encoding/json
+			continue
+		}
+		if field.Tag.Get("json") == "-" && !isParameter(field) {
+			// Fields ignored by encoding/json are never decoded from the request body
+			continue
+		}
+
+		fieldPath := path
+		if !embedded {
+			fieldPath = append(path[:len(path):len(path)], name)
+		}
+
+		fv := rv.Field(i)
+		if tag, ok := field.Tag.Lookup("validate"); ok && tag != "" && tag != "-" && field.IsExported() {
+			valid, err := checkRules(fv, tag, reporter(errs, field, fieldPath))
+			if err != nil {
+				return fmt.Errorf("validate: field %s.%s: %w", rt.Name(), field.Name, err)
+			}
+			if !valid {
+				// Nested values of an invalid field would only add noise
+				continue
+			}
+		}
+		if err := validateNested(errs, fv, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested validates the structs contained in rv, if any.
+func validateNested(errs *httperror.ValidationErrors, rv reflect.Value, path []string) error {
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return validateNested(errs, rv.Elem(), path)
+	case reflect.Struct:
+		return validateStruct(errs, rv, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := validateNested(errs, rv.Index(i), append(path[:len(path):len(path)], strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Keys are visited in sorted order, as encoding/json writes them, so failures are reported
+		// in the same order for the same request
+		keys := make([]string, 0, rv.Len())
+		values := make(map[string]reflect.Value, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, ok := scalar(iter.Key())
+			if !ok {
+				key = fmt.Sprint(iter.Key())
+			}
+			keys = append(keys, key)
+			values[key] = iter.Value()
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := validateNested(errs, values[key], append(path[:len(path):len(path)], key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldName returns the JSON member name of a struct field, and whether the field is an embedded
+// struct whose fields are promoted into the enclosing object as in encoding/json.
+func fieldName(field reflect.StructField) (string, bool) {
+	name := field.Name
+	tag, hasTag := field.Tag.Lookup("json")
+	if hasTag {
+		tagName, _, _ := strings.Cut(tag, ",")
+		if tagName != "" && tag != "-" {
+			// A tag of "-," names the member "-"
+			return tagName, false
+		}
+	}
+
+	if field.Anonymous {
+		t := field.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+// isParameter reports whether the field is decoded from a path or query parameter, as tagged for decode.Params.
+func isParameter(field reflect.StructField) bool {
+	_, query := field.Tag.Lookup("query")
+	_, path := field.Tag.Lookup("path")
+	return query || path
+}
+
+// reporter returns a function recording a failure of the given field. Fields decoded from path or query
+// parameters, as tagged for decode.Params, are located by parameter name; others by JSON Pointer.
+func reporter(errs *httperror.ValidationErrors, field reflect.StructField, path []string) func(detail, code string) {
+	if param := field.Tag.Get("query"); param != "" {
+		return func(detail, code string) {
+			errs.AddParameter(param, detail, code)
+		}
+	}
+	if param := field.Tag.Get("path"); param != "" {
+		return func(detail, code string) {
+			errs.AddPathParameter(param, detail, code)
+		}
+	}
+	return func(detail, code string) {
+		errs.Add(httperror.JSONPointer(path...), detail, code)
+	}
+}
+
+// checkRules applies the rules of a validate tag to rv and reports the first failing rule.
+// It reports whether all rules were satisfied.
+func checkRules(rv reflect.Value, tag string, report func(detail, code string)) (bool, error) {
+	rules, err := parseRules(tag)
+	if err != nil {
+		return false, err
+	}
+
+	isNil := (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil()
+	for _, r := range rules {
+		switch {
+		case r.name == "required":
+			if isNil || isZero(indirect(rv)) {
+				report("is required", "required")
+				return false, nil
+			}
+			continue
+		case r.name == "omitempty":
+			if isNil || isZero(indirect(rv)) {
+				return true, nil
+			}
+			continue
+		case isNil:
+			// Absent optional values are not subject to the remaining rules
+			return true, nil
+		}
+
+		detail, err := r.check(indirect(rv))
+		if err != nil {
+			return false, fmt.Errorf("rule %q: %w", r.name, err)
+		}
+		if detail != "" {
+			report(detail, r.name)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// indirect dereferences pointers and interfaces until it reaches a concrete value.
+func indirect(rv reflect.Value) reflect.Value {
+	for (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// isZero reports whether rv is the zero value, treating empty slices and maps as zero.
+func isZero(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Invalid:
+		return true
+	}
+	return rv.IsZero()
+}
@@ -0,0 +1,256 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gosuda/httpwrap/httperror"
+)
+
+type address struct {
+	City    string `json:"city" validate:"required"`
+	Country string `json:"country" validate:"len=2"`
+}
+
+type audit struct {
+	CreatedBy string `json:"created_by" validate:"required"`
+}
+
+type createUser struct {
+	audit
+	Name     string            `json:"name" validate:"required,max=8"`
+	Email    string            `json:"email" validate:"required,email"`
+	Age      int               `json:"age" validate:"min=0,max=150"`
+	Score    float64           `json:"score,omitempty" validate:"max=1.5"`
+	Role     string            `json:"role" validate:"omitempty,oneof=admin member"`
+	Level    uint              `json:"level" validate:"oneof=1 2 3"`
+	Code     string            `json:"code" validate:"pattern=^[A-Z]{2,3}$"`
+	Nickname *string           `json:"nickname" validate:"min=2"`
+	Tags     []string          `json:"tags" validate:"max=2"`
+	Address  *address          `json:"address" validate:"required"`
+	Previous []address         `json:"previous"`
+	Labels   map[string]string `json:"labels"`
+	internal string            `validate:"required"`
+}
+
+func validUser() *createUser {
+	return &createUser{
+		audit:    audit{CreatedBy: "admin"},
+		Name:     "gopher",
+		Email:    "gopher@example.com",
+		Age:      13,
+		Level:    2,
+		Code:     "KR",
+		Address:  &address{City: "Seoul", Country: "KR"},
+		Previous: []address{{City: "Busan", Country: "KR"}},
+	}
+}
+
+func fieldErrors(t *testing.T, err error) []httperror.FieldError {
+	t.Helper()
+
+	var errs *httperror.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected *httperror.ValidationErrors, got %T: %v", err, err)
+	}
+	return errs.Errors()
+}
+
+func TestStruct_Valid(t *testing.T) {
+	if err := Struct(validUser()); err != nil {
+		t.Errorf("Struct() = %v, want nil", err)
+	}
+}
+
+func TestStruct_Rules(t *testing.T) {
+	short := "x"
+
+	tests := []struct {
+		name     string
+		modify   func(u *createUser)
+		expected httperror.FieldError
+	}{
+		{"required", func(u *createUser) { u.Name = "" }, httperror.FieldError{Pointer: "#/name", Detail: "is required", Code: "required"}},
+		{"max string", func(u *createUser) { u.Name = "고퍼고퍼고퍼고퍼고" }, httperror.FieldError{Pointer: "#/name", Detail: "must be at most 8 characters long", Code: "max"}},
+		{"email", func(u *createUser) { u.Email = "Gopher <gopher@example.com>" }, httperror.FieldError{Pointer: "#/email", Detail: "must be a valid email address", Code: "email"}},
+		{"min int", func(u *createUser) { u.Age = -1 }, httperror.FieldError{Pointer: "#/age", Detail: "must be at least 0", Code: "min"}},
+		{"max int", func(u *createUser) { u.Age = 151 }, httperror.FieldError{Pointer: "#/age", Detail: "must be at most 150", Code: "max"}},
+		{"max float", func(u *createUser) { u.Score = 1.6 }, httperror.FieldError{Pointer: "#/score", Detail: "must be at most 1.5", Code: "max"}},
+		{"oneof string", func(u *createUser) { u.Role = "owner" }, httperror.FieldError{Pointer: "#/role", Detail: "must be one of: admin, member", Code: "oneof"}},
+		{"oneof uint", func(u *createUser) { u.Level = 4 }, httperror.FieldError{Pointer: "#/level", Detail: "must be one of: 1, 2, 3", Code: "oneof"}},
+		{"pattern", func(u *createUser) { u.Code = "kr" }, httperror.FieldError{Pointer: "#/code", Detail: "must match the pattern ^[A-Z]{2,3}$", Code: "pattern"}},
+		{"pointer", func(u *createUser) { u.Nickname = &short }, httperror.FieldError{Pointer: "#/nickname", Detail: "must be at least 2 characters long", Code: "min"}},
+		{"max slice", func(u *createUser) { u.Tags = []string{"a", "b", "c"} }, httperror.FieldError{Pointer: "#/tags", Detail: "must be at most 2 items long", Code: "max"}},
+		{"required pointer", func(u *createUser) { u.Address = nil }, httperror.FieldError{Pointer: "#/address", Detail: "is required", Code: "required"}},
+		{"nested struct", func(u *createUser) { u.Address.Country = "KOR" }, httperror.FieldError{Pointer: "#/address/country", Detail: "must be exactly 2 characters long", Code: "len"}},
+		{"slice element", func(u *createUser) { u.Previous[0].City = "" }, httperror.FieldError{Pointer: "#/previous/0/city", Detail: "is required", Code: "required"}},
+		{"embedded struct", func(u *createUser) { u.CreatedBy = "" }, httperror.FieldError{Pointer: "#/created_by", Detail: "is required", Code: "required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := validUser()
+			tt.modify(u)
+
+			errs := fieldErrors(t, Struct(u))
+			if !reflect.DeepEqual(errs, []httperror.FieldError{tt.expected}) {
+				t.Errorf("Struct() = %+v, want %+v", errs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStruct_ReportsEveryField(t *testing.T) {
+	errs := fieldErrors(t, Struct(&createUser{Level: 1, Code: "KR"}))
+
+	var pointers []string
+	for _, e := range errs {
+		pointers = append(pointers, e.Pointer)
+	}
+	expected := []string{"#/created_by", "#/name", "#/email", "#/address"}
+	if !reflect.DeepEqual(pointers, expected) {
+		t.Errorf("pointers = %v, want %v", pointers, expected)
+	}
+}
+
+func TestStruct_MapValues(t *testing.T) {
+	type order struct {
+		Items map[string]address `json:"items"`
+	}
+
+	errs := fieldErrors(t, Struct(order{Items: map[string]address{"home/main": {Country: "KR"}}}))
+	if len(errs) != 1 || errs[0].Pointer != "#/items/home~1main/city" {
+		t.Errorf("Struct() = %+v, want a failure at #/items/home~1main/city", errs)
+	}
+}
+
+func TestStruct_MapKeysSorted(t *testing.T) {
+	type order struct {
+		Items map[string]address `json:"items"`
+	}
+
+	items := map[string]address{}
+	for _, key := range []string{"d", "b", "e", "a", "c"} {
+		items[key] = address{Country: "KR"}
+	}
+
+	for i := 0; i < 10; i++ {
+		var pointers []string
+		for _, e := range fieldErrors(t, Struct(order{Items: items})) {
+			pointers = append(pointers, e.Pointer)
+		}
+		expected := []string{"#/items/a/city", "#/items/b/city", "#/items/c/city", "#/items/d/city", "#/items/e/city"}
+		if !reflect.DeepEqual(pointers, expected) {
+			t.Fatalf("pointers = %v, want %v", pointers, expected)
+		}
+	}
+}
+
+func TestStruct_IgnoredFields(t *testing.T) {
+	type v struct {
+		Secret string `json:"-" validate:"required"`
+		Dash   string `json:"-," validate:"required"`
+		ID     int    `json:"-" path:"id" validate:"min=1"`
+	}
+
+	errs := fieldErrors(t, Struct(v{}))
+	expected := []httperror.FieldError{
+		{Pointer: "#/-", Detail: "is required", Code: "required"},
+		{Parameter: "id", In: "path", Detail: "must be at least 1", Code: "min"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Struct() = %+v, want %+v", errs, expected)
+	}
+}
+
+func TestStruct_InvalidTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		error string
+	}{
+		{"unknown rule", struct {
+			A string `validate:"uuid"`
+		}{}, `unknown rule "uuid"`},
+		{"missing argument", struct {
+			A string `validate:"min"`
+		}{}, `rule "min" requires an argument`},
+		{"malformed argument", struct {
+			A int `validate:"max=ten"`
+		}{}, `rule "max"`},
+		{"unsupported kind", struct {
+			A bool `validate:"min=1"`
+		}{}, "not supported for bool values"},
+		{"invalid pattern", struct {
+			A string `validate:"pattern=[a-"`
+		}{}, `rule "pattern"`},
+		{"not a struct", "string", "is not a struct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(tt.value)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			var errs *httperror.ValidationErrors
+			if errors.As(err, &errs) {
+				t.Fatalf("Invalid tags should not be reported as validation errors, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("Error() = %q, want it to contain %q", err.Error(), tt.error)
+			}
+		})
+	}
+}
+
+func TestStruct_PatternWithComma(t *testing.T) {
+	type v struct {
+		A string `json:"a" validate:"required,pattern=^a{1,2}$"`
+	}
+
+	if err := Struct(v{A: "aa"}); err != nil {
+		t.Errorf("Struct() = %v, want nil", err)
+	}
+	if err := Struct(v{A: "aaa"}); err == nil {
+		t.Error("Expected a validation error")
+	}
+}
+
+func TestStruct_PromotedFieldsOfUnexportedEmbeddedStruct(t *testing.T) {
+	type base struct {
+		Kind  int           `json:"kind" validate:"oneof=1 2"`
+		Items map[int]audit `json:"items"`
+	}
+	type v struct {
+		base
+	}
+
+	errs := fieldErrors(t, Struct(v{base{Kind: 3, Items: map[int]audit{7: {}}}}))
+
+	var pointers []string
+	for _, e := range errs {
+		pointers = append(pointers, e.Pointer)
+	}
+	if !reflect.DeepEqual(pointers, []string{"#/kind", "#/items/7/created_by"}) {
+		t.Errorf("pointers = %v, want [#/kind #/items/7/created_by]", pointers)
+	}
+}
+
+func TestStruct_Parameters(t *testing.T) {
+	type listUsers struct {
+		ID   int `path:"id" validate:"min=1"`
+		Page int `query:"page" validate:"min=1"`
+	}
+
+	errs := fieldErrors(t, Struct(listUsers{}))
+	expected := []httperror.FieldError{
+		{Parameter: "id", In: "path", Detail: "must be at least 1", Code: "min"},
+		{Parameter: "page", In: "query", Detail: "must be at least 1", Code: "min"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Struct() = %+v, want %+v", errs, expected)
+	}
+}
@@ -4,145 +4,297 @@
 package chiwrap
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/internal/response"
+	"github.com/gosuda/httpwrap/render"
 )
 
 // Router wraps chi.Router with enhanced error handling capabilities.
 // It provides automatic error handling and supports custom error callbacks.
 type Router struct {
-	router      chi.Router
-	errCallback func(err error)
+	router       chi.Router
+	root         chi.Router // Router of the root Router, against which allowed methods are matched
+	errorHandler *response.ErrorHandler
+}
+
+// Option configures a Router.
+type Option func(*Router)
+
+// WithRenderer sets the Renderer used to convert handler errors into HTTP responses.
+// If renderer is nil, the default renderer is kept.
+func WithRenderer(renderer render.Renderer) Option {
+	return func(r *Router) {
+		if renderer != nil {
+			r.errorHandler.Renderer = renderer
+		}
+	}
+}
+
+// WithEventCallback sets a callback that receives a *render.ErrorEvent for every error handled by the Router,
+// describing the request, route pattern, response status, problem type and latency along with the error.
+// It is called after the error callback.
+func WithEventCallback(callback func(event *render.ErrorEvent)) Option {
+	return func(r *Router) {
+		r.errorHandler.EventCallback = callback
+	}
+}
+
+// WithAbortAfterCommit makes the Router abort the connection when a handler returns an error, or panics,
+// after it started writing the response, by panicking with http.ErrAbortHandler once the error is reported.
+// Without it the response is ended normally, and the client may mistake a truncated body for a complete one.
+func WithAbortAfterCommit() Option {
+	return func(r *Router) {
+		r.errorHandler.AbortAfterCommit = true
+	}
 }
 
 // NewRouter creates a new Router with the specified error callback function.
 // If errCallback is nil, a no-op function is used.
-func NewRouter(errCallback func(err error)) *Router {
+func NewRouter(errCallback func(err error), opts ...Option) *Router {
 	if errCallback == nil {
 		errCallback = func(err error) {}
 	}
-	return &Router{
-		router:      chi.NewRouter(),
-		errCallback: errCallback,
+	router := chi.NewRouter()
+	r := &Router{
+		router: router,
+		root:   router,
+		errorHandler: &response.ErrorHandler{
+			Renderer:      render.New(),
+			ErrorCallback: errCallback,
+			Route:         routePattern,
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	r.NotFound(notFound)
+	r.MethodNotAllowed(methodNotAllowed)
+	return r
 }
 
 // HandlerFunc defines a function signature for HTTP handlers that can return errors.
 // This allows for cleaner error handling in HTTP handlers with chi router.
 type HandlerFunc func(writer http.ResponseWriter, request *http.Request) error
 
-// handleError processes an error and sends appropriate HTTP response with content type support.
-func (r *Router) handleError(writer http.ResponseWriter, err error) {
-	he := &httperror.HttpError{}
-	switch errors.As(err, &he) {
-	case true:
-		// Set Content-Type if specified in HttpError
-		if he.ContentType != "" {
-			writer.Header().Set("Content-Type", he.ContentType)
-			writer.WriteHeader(he.Code)
-			writer.Write([]byte(he.Message))
-		} else {
-			http.Error(writer, he.Message, he.Code)
-		}
-	case false:
-		http.Error(writer, err.Error(), http.StatusInternalServerError)
-	}
-	r.errCallback(err)
+// wrap converts a HandlerFunc into an http.HandlerFunc that renders the returned error.
+// Panics are recovered and rendered as a 500 Internal Server Error problem, and reported to the
+// error callback as a *httperror.PanicError; http.ErrAbortHandler is re-panicked for net/http.
+// Errors returned after the handler started writing the response are not rendered; see render.CommittedError.
+func (r *Router) wrap(handler HandlerFunc) http.HandlerFunc {
+	return r.errorHandler.Wrap(handler)
 }
 
 // Handle registers a new handler for the given pattern with automatic error handling.
 // If the handler returns an error, it will be automatically converted to an appropriate HTTP response.
 func (r *Router) Handle(pattern string, handler HandlerFunc) {
-	r.router.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.HandleFunc(pattern, r.wrap(handler))
 }
 
 // Get registers a new GET handler for the given pattern with automatic error handling.
 func (r *Router) Get(pattern string, handler HandlerFunc) {
-	r.router.Get(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.Get(pattern, r.wrap(handler))
 }
 
 // Post registers a new POST handler for the given pattern with automatic error handling.
 func (r *Router) Post(pattern string, handler HandlerFunc) {
-	r.router.Post(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.Post(pattern, r.wrap(handler))
 }
 
 // Put registers a new PUT handler for the given pattern with automatic error handling.
 func (r *Router) Put(pattern string, handler HandlerFunc) {
-	r.router.Put(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.Put(pattern, r.wrap(handler))
 }
 
 // Delete registers a new DELETE handler for the given pattern with automatic error handling.
 func (r *Router) Delete(pattern string, handler HandlerFunc) {
-	r.router.Delete(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.Delete(pattern, r.wrap(handler))
 }
 
 // Patch registers a new PATCH handler for the given pattern with automatic error handling.
 func (r *Router) Patch(pattern string, handler HandlerFunc) {
-	r.router.Patch(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.Patch(pattern, r.wrap(handler))
 }
 
 // Options registers a new OPTIONS handler for the given pattern with automatic error handling.
 func (r *Router) Options(pattern string, handler HandlerFunc) {
-	r.router.Options(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.Options(pattern, r.wrap(handler))
 }
 
 // Head registers a new HEAD handler for the given pattern with automatic error handling.
 func (r *Router) Head(pattern string, handler HandlerFunc) {
-	r.router.Head(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			r.handleError(writer, err)
-		}
-	})
+	r.router.Head(pattern, r.wrap(handler))
+}
+
+// Connect registers a new CONNECT handler for the given pattern with automatic error handling.
+func (r *Router) Connect(pattern string, handler HandlerFunc) {
+	r.router.Connect(pattern, r.wrap(handler))
+}
+
+// Trace registers a new TRACE handler for the given pattern with automatic error handling.
+func (r *Router) Trace(pattern string, handler HandlerFunc) {
+	r.router.Trace(pattern, r.wrap(handler))
+}
+
+// Method registers a new handler for the given HTTP method and pattern with automatic error handling,
+// for methods without a dedicated helper. Like Handle, it takes a HandlerFunc, so it corresponds to
+// chi's MethodFunc as well as its Method. Non-standard methods must first be registered with chi.RegisterMethod.
+func (r *Router) Method(method, pattern string, handler HandlerFunc) {
+	r.router.MethodFunc(method, pattern, r.wrap(handler))
+}
+
+// MiddlewareFunc defines a middleware that can return errors. It receives the next handler in the chain
+// and returns a HandlerFunc, which calls next to continue or returns an error to stop the request.
+type MiddlewareFunc func(next http.Handler) HandlerFunc
+
+// Middleware converts a MiddlewareFunc into a standard chi middleware for Use, With and Group.
+// Errors returned by the middleware are rendered and reported like those of the Router's handlers.
+func (r *Router) Middleware(middleware MiddlewareFunc) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return r.wrap(middleware(next))
+	}
+}
+
+// Use appends middleware to the middleware stack of the Router. Standard chi middleware can be used directly,
+// and middleware that returns errors can be converted with Middleware.
+// As with chi, middleware must be registered before the routes of the Router.
+func (r *Router) Use(middlewares ...func(http.Handler) http.Handler) {
+	r.router.Use(middlewares...)
+}
+
+// With returns a new inline Router that applies the given middleware, in addition to that of the Router,
+// to the handlers registered on it. The inline Router inherits the settings of the Router.
+func (r *Router) With(middlewares ...func(http.Handler) http.Handler) *Router {
+	return r.sub(r.router.With(middlewares...))
+}
+
+// Group creates a new inline Router with a copy of the middleware stack, and passes it to callback
+// to register middleware and handlers that apply only to the group. The inline Router is returned.
+func (r *Router) Group(callback func(r *Router)) *Router {
+	return r.sub(r.router.Group(func(router chi.Router) {
+		callback(r.sub(router))
+	}))
 }
 
 // Route creates a new sub-router for the given pattern.
 // The callback function receives a new Router instance that inherits the error callback.
 func (r *Router) Route(pattern string, callback func(r *Router)) {
 	r.router.Route(pattern, func(router chi.Router) {
-		callback(&Router{
-			router:      router,
-			errCallback: r.errCallback,
-		})
+		callback(r.sub(router))
 	})
 }
 
+// sub returns a Router registering handlers on router with the same settings as r.
+func (r *Router) sub(router chi.Router) *Router {
+	return &Router{
+		router:       router,
+		root:         r.root,
+		errorHandler: r.errorHandler,
+	}
+}
+
 // Mount attaches a sub-router or http.Handler to the routing pattern.
 func (r *Router) Mount(pattern string, subRouter http.Handler) {
 	r.router.Mount(pattern, subRouter)
 }
 
+// NotFound sets the handler for requests that match no route, with automatic error handling.
+// It applies to sub-routers that do not have their own. By default, a 404 Not Found problem is rendered.
+func (r *Router) NotFound(handler HandlerFunc) {
+	r.router.NotFound(r.wrap(handler))
+}
+
+// MethodNotAllowed sets the handler for requests whose path matches a route, but not with the request method,
+// with automatic error handling. It applies to sub-routers that do not have their own. The Allow header,
+// listing the methods of the routes matching the path, is set before handler is called.
+// By default, a 405 Method Not Allowed problem is rendered.
+func (r *Router) MethodNotAllowed(handler HandlerFunc) {
+	wrapped := r.wrap(handler)
+	r.router.MethodNotAllowed(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Allow", strings.Join(r.allowedMethods(request), ", "))
+		wrapped(writer, request)
+	})
+}
+
+// notFound is the default NotFound handler.
+func notFound(writer http.ResponseWriter, request *http.Request) error {
+	return httperror.NotFoundProblem9457("The requested resource was not found.")
+}
+
+// methodNotAllowed is the default MethodNotAllowed handler.
+func methodNotAllowed(writer http.ResponseWriter, request *http.Request) error {
+	return httperror.MethodNotAllowedProblem9457(fmt.Sprintf("The %s method is not allowed for the requested resource.", request.Method))
+}
+
+// allowedMethods returns the sorted methods of the routes matching the path of request.
+// chi does not expose the methods it found while routing to custom handlers, so every method
+// registered on the Router is matched against the routing tree again.
+func (r *Router) allowedMethods(request *http.Request) []string {
+	path := rootPath(request)
+	seen := make(map[string]bool)
+	var allowed []string
+	_ = chi.Walk(r.root, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if !seen[method] {
+			seen[method] = true
+			if r.root.Match(chi.NewRouteContext(), method, path) {
+				allowed = append(allowed, method)
+			}
+		}
+		return nil
+	})
+	sort.Strings(allowed)
+	return allowed
+}
+
+// rootPathKey is the context key of the path of a request relative to a root Router mounted on another router.
+type rootPathKey struct{}
+
+// rootPath returns the path of request relative to the root Router, as routed by chi.
+func rootPath(request *http.Request) string {
+	if path, ok := request.Context().Value(rootPathKey{}).(string); ok {
+		return path
+	}
+	if request.URL.RawPath != "" {
+		return request.URL.RawPath
+	}
+	if request.URL.Path != "" {
+		return request.URL.Path
+	}
+	return "/"
+}
+
+// Chi returns the underlying chi.Router, for features of chi that Router does not wrap.
+// Handlers registered on it directly do not get automatic error handling.
+func (r *Router) Chi() chi.Router {
+	return r.router
+}
+
+// Walk calls fn for every route registered on the Router, including those of sub-routers,
+// with the route's method, pattern, handler and middleware. See chi.Walk.
+func (r *Router) Walk(fn chi.WalkFunc) error {
+	return chi.Walk(r.router, fn)
+}
+
+// ServeHTTP implements the http.Handler interface, delegating to the underlying chi.Router.
 func (r *Router) ServeHTTP(writer http.ResponseWriter, reader *http.Request) {
+	if rctx := chi.RouteContext(reader.Context()); rctx != nil && rctx.RoutePath != "" {
+		// Mounted on another chi router, which routes the remaining path only
+		reader = reader.WithContext(context.WithValue(reader.Context(), rootPathKey{}, rctx.RoutePath))
+	}
 	r.router.ServeHTTP(writer, reader)
 }
+
+// routePattern returns the route pattern that matched request, such as "/users/{id}".
+func routePattern(request *http.Request) string {
+	if rctx := chi.RouteContext(request.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
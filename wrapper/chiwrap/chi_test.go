@@ -3,14 +3,21 @@ package chiwrap_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/render"
+	"github.com/gosuda/httpwrap/validate"
 	"github.com/gosuda/httpwrap/wrapper/chiwrap"
 )
 
@@ -262,3 +269,408 @@ func TestRouter_HandleWithContentType(t *testing.T) {
 
 	svr.Shutdown(context.Background())
 }
+
+func TestRouter_WithRenderer(t *testing.T) {
+	renderer := render.RendererFunc(func(request *http.Request, err error) *render.Response {
+		return render.Text(http.StatusTeapot, "custom: "+err.Error())
+	})
+
+	r := chiwrap.NewRouter(nil, chiwrap.WithRenderer(renderer))
+	r.Route("/sub", func(r *chiwrap.Router) {
+		r.Get("/fail", func(writer http.ResponseWriter, request *http.Request) error {
+			return httperror.NotFound("missing")
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/sub/fail", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("Expected status code %d, got %d", http.StatusTeapot, w.Code)
+	}
+	if string(bytes.TrimSpace(w.Body.Bytes())) != "custom: 404: missing" {
+		t.Fatalf("Unexpected response body: %s", w.Body.String())
+	}
+}
+
+func TestRouter_NegotiatesErrorFormat(t *testing.T) {
+	r := chiwrap.NewRouter(nil)
+	r.Get("/users/{id}", func(writer http.ResponseWriter, request *http.Request) error {
+		return httperror.NotFoundProblem9457("User not found")
+	})
+
+	tests := []struct {
+		accept              string
+		expectedContentType string
+	}{
+		{"", "application/problem+json"},
+		{"application/problem+xml", "application/problem+xml"},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", "text/html; charset=utf-8"},
+		{"text/plain", "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Accept %q: expected status code 404, got %d", tt.accept, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.expectedContentType {
+			t.Errorf("Accept %q: expected content type %s, got %s", tt.accept, tt.expectedContentType, ct)
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: expected Vary: Accept, got %q", tt.accept, w.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestRouter_ValidationProblem(t *testing.T) {
+	type createUser struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	r := chiwrap.NewRouter(nil)
+	r.Post("/users", func(writer http.ResponseWriter, request *http.Request) error {
+		var body createUser
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			return httperror.BadRequest("invalid JSON")
+		}
+		if err := validate.Struct(&body); err != nil {
+			return err
+		}
+		writer.WriteHeader(http.StatusCreated)
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"email":"not an email"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code 400, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("Expected content type application/problem+json, got %s", ct)
+	}
+
+	var problem struct {
+		Errors []httperror.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to unmarshal problem: %v", err)
+	}
+	if len(problem.Errors) != 2 || problem.Errors[0].Pointer != "#/name" || problem.Errors[1].Pointer != "#/email" {
+		t.Fatalf("Unexpected errors: %+v", problem.Errors)
+	}
+}
+
+func TestRouter_Typed(t *testing.T) {
+	type updateUser struct {
+		ID     int    `path:"id" validate:"min=1"`
+		Notify bool   `query:"notify"`
+		Name   string `json:"name" validate:"required"`
+	}
+	type user struct {
+		ID     int    `json:"id"`
+		Name   string `json:"name"`
+		Notify bool   `json:"notify"`
+	}
+
+	router := chiwrap.NewRouter(nil)
+	router.Put("/users/{id}", chiwrap.Typed(func(ctx context.Context, req updateUser) (user, error) {
+		return user{ID: req.ID, Name: req.Name, Notify: req.Notify}, nil
+	}))
+
+	tests := []struct {
+		name           string
+		target         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Decodes body and parameters", "/users/7?notify=true", `{"name":"gopher"}`, http.StatusOK, `{"id":7,"name":"gopher","notify":true}`},
+		{"Invalid parameters and body", "/users/0", `{"name":""}`, http.StatusBadRequest,
+			`"errors":[{"parameter":"id","in":"path","detail":"must be at least 1","code":"min"},{"pointer":"#/name","detail":"is required","code":"required"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_RecoversPanics(t *testing.T) {
+	var reported error
+	router := chiwrap.NewRouter(func(err error) { reported = err })
+	router.Route("/api", func(r *chiwrap.Router) {
+		r.Get("/panic", func(w http.ResponseWriter, r *http.Request) error {
+			var m map[string]int
+			m["boom"]++
+			return nil
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/api/panic", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	var pe *httperror.PanicError
+	if !errors.As(reported, &pe) {
+		t.Fatalf("Expected a *httperror.PanicError to be reported, got %v", reported)
+	}
+	if !strings.Contains(pe.Error(), "assignment to entry in nil map") {
+		t.Errorf("Error() = %q, want the runtime error", pe.Error())
+	}
+}
+
+func TestRouter_ErrorAfterCommit(t *testing.T) {
+	var reported error
+	router := chiwrap.NewRouter(func(err error) { reported = err })
+	router.Get("/stream", func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte("partial"))
+		return httperror.InternalServerErrorProblem9457("too late")
+	})
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("Expected the partial response to be left untouched, got %d %q", w.Code, w.Body.String())
+	}
+	var committed *render.CommittedError
+	if !errors.As(reported, &committed) {
+		t.Errorf("Expected a *render.CommittedError to be reported, got %v", reported)
+	}
+}
+
+func TestRouter_EventCallback(t *testing.T) {
+	var event *render.ErrorEvent
+	router := chiwrap.NewRouter(nil, chiwrap.WithEventCallback(func(e *render.ErrorEvent) {
+		event = e
+	}))
+	router.Route("/api", func(r *chiwrap.Router) {
+		r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+			return errors.New("database unavailable")
+		})
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/users/7", nil))
+
+	if event == nil {
+		t.Fatal("Expected an event")
+	}
+	if event.Route != "/api/users/{id}" {
+		t.Errorf("Route = %q, want /api/users/{id}", event.Route)
+	}
+	if event.Status != http.StatusInternalServerError || !event.IsServerError() {
+		t.Errorf("Status = %d, want a 500 server error", event.Status)
+	}
+	if event.Reference == "" || !strings.Contains(event.Err.Error(), event.Reference) {
+		t.Errorf("Reference = %q, Err = %v", event.Reference, event.Err)
+	}
+}
+
+func TestRouter_Middleware(t *testing.T) {
+	chi.RegisterMethod("PROPFIND")
+
+	var reported []error
+	router := chiwrap.NewRouter(func(err error) { reported = append(reported, err) })
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "42")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	requireToken := router.Middleware(func(next http.Handler) chiwrap.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return httperror.UnauthorizedProblem9457("A valid token is required.")
+			}
+			next.ServeHTTP(w, r)
+			return nil
+		}
+	})
+
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte("ok"))
+		return nil
+	}
+	router.Get("/public", ok)
+	router.With(requireToken).Get("/inline", ok)
+	router.Group(func(r *chiwrap.Router) {
+		r.Use(requireToken)
+		r.Get("/private", ok)
+		r.Method("PROPFIND", "/files", ok)
+	})
+	router.Connect("/tunnel", ok)
+	router.Trace("/trace", ok)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		authorization  string
+		expectedStatus int
+	}{
+		{"Without middleware", "GET", "/public", "", http.StatusOK},
+		{"With rejects", "GET", "/inline", "", http.StatusUnauthorized},
+		{"With accepts", "GET", "/inline", "Bearer secret", http.StatusOK},
+		{"Group rejects", "GET", "/private", "", http.StatusUnauthorized},
+		{"Group accepts", "GET", "/private", "Bearer secret", http.StatusOK},
+		{"Method", "PROPFIND", "/files", "Bearer secret", http.StatusOK},
+		{"Connect", "CONNECT", "/tunnel", "", http.StatusOK},
+		{"Trace", "TRACE", "/trace", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Header().Get("X-Request-Id") != "42" {
+				t.Error("Expected the standard middleware to run")
+			}
+			if tt.expectedStatus == http.StatusUnauthorized && w.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Expected a problem, got %s", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	if len(reported) != 2 {
+		t.Errorf("Expected the middleware errors to be reported, got %v", reported)
+	}
+}
+
+func TestRouter_Walk(t *testing.T) {
+	router := chiwrap.NewRouter(nil)
+	noop := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	router.Get("/users", noop)
+	router.Route("/users/{id}", func(r *chiwrap.Router) {
+		r.Delete("/", noop)
+	})
+	router.Chi().Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	var routes []string
+	err := router.Walk(func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	expected := []string{"GET /health", "GET /users", "DELETE /users/{id}/"}
+	if strings.Join(routes, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Walk() visited %v, want %v", routes, expected)
+	}
+}
+
+func TestRouter_NotFoundAndMethodNotAllowed(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	router := chiwrap.NewRouter(nil)
+	router.Get("/users", noop)
+	router.Post("/users", noop)
+	router.Route("/api", func(r *chiwrap.Router) {
+		r.Get("/orders/{id}", noop)
+		r.Delete("/orders/{id}", noop)
+	})
+
+	parent := chi.NewRouter()
+	parent.Mount("/v1", router)
+
+	tests := []struct {
+		name           string
+		handler        http.Handler
+		method         string
+		target         string
+		expectedStatus int
+		expectedAllow  string
+	}{
+		{"Unknown route", router, "GET", "/missing", http.StatusNotFound, ""},
+		{"Unknown route in sub-router", router, "GET", "/api/missing", http.StatusNotFound, ""},
+		{"Wrong method", router, "DELETE", "/users", http.StatusMethodNotAllowed, "GET, POST"},
+		{"Wrong method in sub-router", router, "PUT", "/api/orders/7", http.StatusMethodNotAllowed, "DELETE, GET"},
+		{"Wrong method when mounted", parent, "PATCH", "/v1/api/orders/7", http.StatusMethodNotAllowed, "DELETE, GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			tt.handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Expected content type application/problem+json, got %s", w.Header().Get("Content-Type"))
+			}
+			if w.Header().Get("Allow") != tt.expectedAllow {
+				t.Errorf("Allow = %q, want %q", w.Header().Get("Allow"), tt.expectedAllow)
+			}
+		})
+	}
+}
+
+func TestRouter_CustomNotFound(t *testing.T) {
+	var reported error
+	router := chiwrap.NewRouter(func(err error) { reported = err })
+	router.Get("/users", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NotFoundProblem9457("No route matches " + r.URL.Path + ".").WithType("https://example.com/probs/no-route")
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.MethodNotAllowedProblem9457("Allowed methods: " + w.Header().Get("Allow") + ".")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "https://example.com/probs/no-route") {
+		t.Errorf("Expected the custom 404 problem, got %d %s", w.Code, w.Body.String())
+	}
+	if reported == nil {
+		t.Error("Expected the error to be reported")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+
+	if w.Code != http.StatusMethodNotAllowed || !strings.Contains(w.Body.String(), "Allowed methods: GET.") {
+		t.Errorf("Expected the custom 405 problem, got %d %s", w.Code, w.Body.String())
+	}
+}
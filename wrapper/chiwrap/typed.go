@@ -0,0 +1,46 @@
+package chiwrap
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gosuda/httpwrap/decode"
+	"github.com/gosuda/httpwrap/internal/typed"
+)
+
+// TypedHandlerFunc defines a handler that receives a decoded request value and returns a response value.
+// See Typed for how requests are decoded and responses are encoded.
+type TypedHandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// Typed converts a TypedHandlerFunc into a HandlerFunc that can be registered with Handle.
+//
+// The request is decoded with decode.Request: the JSON body, if any, is decoded into Req, then fields tagged
+// `path:"name"` are set from the URL parameters of the route and fields tagged `query:"name"` from the query
+// string, and finally structs are validated with validate.Struct. Malformed and invalid requests are rendered
+// as problems without calling handler.
+//
+// The response is encoded as JSON with status 200 OK, or the status returned by its StatusCode method if Resp
+// implements httperror.StatusCoder. Nil pointer responses and 204 No Content responses have no body.
+// Errors returned by handler are rendered like those of any other HandlerFunc.
+func Typed[Req, Resp any](handler TypedHandlerFunc[Req, Resp], opts ...decode.Option) HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		var req Req
+		if err := decode.Request(writer, request, &req, pathValue(request), opts...); err != nil {
+			return err
+		}
+		resp, err := handler(request.Context(), req)
+		if err != nil {
+			return err
+		}
+		return typed.Write(writer, resp)
+	}
+}
+
+// pathValue returns a function looking up the URL parameters of the route matching request.
+func pathValue(request *http.Request) func(name string) string {
+	return func(name string) string {
+		return chi.URLParam(request, name)
+	}
+}
@@ -0,0 +1,18 @@
+package fiberwrap
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/gosuda/httpwrap/decode"
+)
+
+// DecodeJSON decodes the JSON body of the request into v, which must be a non-nil pointer.
+// It applies the same rules as decode.JSON and returns the same problems for malformed requests,
+// so handlers can return its error directly. Note that Fiber's own BodyLimit, 4 MiB by default,
+// rejects larger bodies before the handler runs.
+func DecodeJSON(c *fiber.Ctx, v interface{}, opts ...decode.Option) error {
+	if err := decode.CheckContentType(c.Get(fiber.HeaderContentType)); err != nil {
+		return err
+	}
+	return decode.Bytes(c.Body(), v, opts...)
+}
@@ -5,56 +5,231 @@ package fiberwrap
 
 import (
 	"errors"
+	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
 
 	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/render"
 )
 
 // Wrapper wraps a Fiber application with error handling capabilities.
 type Wrapper struct {
-	app *fiber.App
+	app           *fiber.App
+	router        fiber.Router // app, or the group handlers are registered on
+	renderer      render.Renderer
+	eventCallback func(event *render.ErrorEvent)
 }
 
-// NewWrapper creates a new Wrapper with a default Fiber application.
-func NewWrapper() *Wrapper {
-	return &Wrapper{
-		app: fiber.New(),
+// Option configures a Wrapper.
+type Option func(*Wrapper)
+
+// WithRenderer sets the Renderer used to convert handler errors into HTTP responses.
+// If renderer is nil, the default renderer is kept. The default renderer is created with render.WithBareText,
+// so plain text errors are written exactly as Fiber's Ctx.SendString writes them.
+func WithRenderer(renderer render.Renderer) Option {
+	return func(a *Wrapper) {
+		if renderer != nil {
+			a.renderer = renderer
+		}
 	}
 }
 
+// WithEventCallback sets a callback that receives a *render.ErrorEvent for every error handled by the Wrapper,
+// describing the request, route pattern, response status, problem type and latency along with the error.
+// The Request of the event is converted from the Fiber request and shares its memory, so it must not be
+// retained after the callback returns.
+func WithEventCallback(callback func(event *render.ErrorEvent)) Option {
+	return func(a *Wrapper) {
+		a.eventCallback = callback
+	}
+}
+
+// NewWrapper creates a new Wrapper with a default Fiber application.
+// The application's ErrorHandler renders errors like the Wrapper's handlers do, so errors from middleware,
+// from handlers registered directly on the application and Fiber's own errors are rendered consistently.
+func NewWrapper(opts ...Option) *Wrapper {
+	a := newWrapper(nil, opts)
+	a.app = fiber.New(fiber.Config{ErrorHandler: a.errorHandler})
+	a.router = a.app
+	return a
+}
+
 // WithApp creates a new Wrapper with an existing Fiber application.
-func WithApp(app *fiber.App) *Wrapper {
-	return &Wrapper{
-		app: app,
+// To render errors outside of the Wrapper's handlers consistently, install ErrorHandler as the
+// application's fiber.Config.ErrorHandler.
+func WithApp(app *fiber.App, opts ...Option) *Wrapper {
+	return newWrapper(app, opts)
+}
+
+// newWrapper creates a new Wrapper with the given options applied.
+func newWrapper(app *fiber.App, opts []Option) *Wrapper {
+	a := &Wrapper{
+		app:      app,
+		router:   app,
+		renderer: render.New(render.WithBareText()),
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// ErrorHandler returns a fiber.ErrorHandler that renders errors with the same rules as the handlers of a Wrapper
+// configured with opts. Install it as fiber.Config.ErrorHandler so that errors returned by middleware and by
+// handlers registered directly on the application, and Fiber's own errors such as 404 Not Found for unknown
+// routes, are rendered as problem details too. The Latency of the events it reports is zero, since the time
+// the request started is unknown.
+func ErrorHandler(opts ...Option) fiber.ErrorHandler {
+	return newWrapper(nil, opts).errorHandler
+}
+
+// errorHandler implements fiber.ErrorHandler.
+func (a *Wrapper) errorHandler(c *fiber.Ctx, err error) error {
+	return a.handleError(c, time.Time{}, err)
 }
 
 // HandlerFunc defines a handler function that can return an error.
 type HandlerFunc func(c *fiber.Ctx) error
 
-// Handle registers a handler function for the given HTTP method and path with error handling.
-// It automatically converts httperror.HttpError instances to appropriate HTTP responses
-// with proper status codes and content types.
-func (a *Wrapper) Handle(method, path string, handler HandlerFunc) {
-	a.app.Add(method, path, func(c *fiber.Ctx) error {
-		if err := handler(c); err != nil {
-			he := &httperror.HttpError{}
-			switch errors.As(err, &he) {
-			case true:
-				// Set Content-Type if specified in HttpError
-				if he.ContentType != "" {
-					c.Set("Content-Type", he.ContentType)
-				}
-				return c.Status(he.Code).SendString(he.ErrorMessage())
-			case false:
-				return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+// handleError renders the error through the configured Renderer, writes the response to the Fiber context
+// and reports it to the event callback. start is the time the handler was called, or zero if unknown.
+func (a *Wrapper) handleError(c *fiber.Ctx, start time.Time, err error) error {
+	err = fiberProblem(err)
+	r := request(c)
+	resp := a.renderer.Render(r, err)
+	writeErr := write(c, resp)
+	if a.eventCallback != nil {
+		a.eventCallback(&render.ErrorEvent{
+			Err:         render.Report(err, resp),
+			Request:     r,
+			Route:       c.Route().Path,
+			Status:      resp.Status,
+			ProblemType: resp.ProblemType,
+			Reference:   resp.Reference,
+			Latency:     latency(start),
+		})
+	}
+	return writeErr
+}
+
+// fiberProblem converts a *fiber.Error, such as fiber.ErrNotFound, into the equivalent problem so that it is
+// rendered like the errors of the httperror package. Status codes with a problem type registered in
+// httperror.DefaultRegistry use that type, as the problem constructors do, so that fiber.ErrNotFound and
+// httperror.NotFoundProblem9457 share a type URI; other status codes use "about:blank".
+// The message of the *fiber.Error is kept as the detail, and the error remains available as its cause.
+// A *fiber.Error wrapped by another error is only converted if no httperror.StatusCoder precedes it in the
+// chain, so a problem whose cause is a *fiber.Error keeps its own status. Other errors are returned as is.
+func fiberProblem(err error) error {
+	fe, ok := err.(*fiber.Error)
+	if !ok {
+		var sc httperror.StatusCoder
+		if errors.As(err, &sc) || !errors.As(err, &fe) {
+			return err
+		}
+	}
+	title := http.StatusText(fe.Code)
+	if title == "" {
+		return httperror.NewRFC9457Error(fe.Code, fe.Message, "").WithCause(err)
+	}
+	detail := fe.Message
+	if detail == title {
+		// Fiber's predefined errors only repeat the status text
+		detail = ""
+	}
+	if pt, ok := httperror.DefaultRegistry.LookupStatus(fe.Code); ok {
+		return httperror.DefaultRegistry.New(pt.URI, detail).WithCause(err)
+	}
+	return httperror.NewRFC9457Error(fe.Code, title, detail).WithCause(err)
+}
+
+// latency returns the time elapsed since start, or zero if start is unknown.
+func latency(start time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	return time.Since(start)
+}
+
+// wrap converts a HandlerFunc into a fiber.Handler that renders the returned error.
+// Panics are recovered and rendered as a 500 Internal Server Error problem for a *httperror.PanicError
+// carrying the stack trace, instead of taking the connection down.
+func (a *Wrapper) wrap(handler HandlerFunc) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		start := time.Now()
+		defer func() {
+			if v := recover(); v != nil {
+				err = a.handleError(c, start, httperror.NewPanicError(v))
 			}
+		}()
+		if err := handler(c); err != nil {
+			return a.handleError(c, start, err)
 		}
 		return nil
+	}
+}
+
+// wrapAll converts HandlerFuncs into fiber.Handlers with wrap.
+func (a *Wrapper) wrapAll(handlers []HandlerFunc) []fiber.Handler {
+	wrapped := make([]fiber.Handler, len(handlers))
+	for i, handler := range handlers {
+		wrapped[i] = a.wrap(handler)
+	}
+	return wrapped
+}
+
+// sub returns a Wrapper registering handlers on router with the same settings as a.
+func (a *Wrapper) sub(router fiber.Router) *Wrapper {
+	return &Wrapper{
+		app:           a.app,
+		router:        router,
+		renderer:      a.renderer,
+		eventCallback: a.eventCallback,
+	}
+}
+
+// Handle registers a handler function for the given HTTP method and path with error handling.
+// It automatically converts handler errors to appropriate HTTP responses through the configured Renderer.
+// Panics are recovered and rendered as a 500 Internal Server Error problem for a *httperror.PanicError
+// carrying the stack trace, instead of taking the connection down.
+func (a *Wrapper) Handle(method, path string, handler HandlerFunc) {
+	a.router.Add(method, path, a.wrap(handler))
+}
+
+// Use registers middleware for every path of the Wrapper, with the same error handling as Handle.
+// Middleware calls c.Next to continue with the next handler, and may return an error instead to stop
+// the request, such as a 401 Unauthorized problem.
+func (a *Wrapper) Use(middleware ...HandlerFunc) {
+	handlers := a.wrapAll(middleware)
+	args := make([]interface{}, len(handlers))
+	for i, handler := range handlers {
+		args[i] = handler
+	}
+	a.router.Use(args...)
+}
+
+// Group creates a new Wrapper for the paths starting with prefix, which runs the given middleware before
+// the handlers registered on it. The group inherits the Renderer and event callback of the Wrapper.
+func (a *Wrapper) Group(prefix string, middleware ...HandlerFunc) *Wrapper {
+	return a.sub(a.router.Group(prefix, a.wrapAll(middleware)...))
+}
+
+// Route creates a new group for the given prefix and passes it to callback to register its handlers,
+// like chiwrap.Router.Route. The group inherits the Renderer and event callback of the Wrapper.
+func (a *Wrapper) Route(prefix string, callback func(w *Wrapper)) {
+	a.router.Route(prefix, func(router fiber.Router) {
+		callback(a.sub(router))
 	})
 }
 
+// Mount attaches a separate Fiber application, such as the App of another Wrapper, at prefix.
+// Errors of the mounted application are rendered by its own ErrorHandler.
+func (a *Wrapper) Mount(prefix string, app *fiber.App) {
+	a.router.Mount(prefix, app)
+}
+
 // Get registers a GET handler for the given path.
 func (a *Wrapper) Get(path string, handler HandlerFunc) {
 	a.Handle(fiber.MethodGet, path, handler)
@@ -100,7 +275,40 @@ func (a *Wrapper) Trace(path string, handler HandlerFunc) {
 	a.Handle(fiber.MethodTrace, path, handler)
 }
 
-// App returns the underlying Fiber application instance.
+// App returns the underlying Fiber application instance. For groups, it is the application of the root Wrapper.
 func (a *Wrapper) App() *fiber.App {
 	return a.app
 }
+
+// request converts the Fiber request into an *http.Request so it can be inspected by a Renderer.
+func request(c *fiber.Ctx) *http.Request {
+	r := &http.Request{}
+	if err := fasthttpadaptor.ConvertRequest(c.Context(), r, true); err != nil {
+		// Fall back to the parts a Renderer is most likely to inspect
+		r.Method = c.Method()
+		r.Header = make(http.Header)
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			r.Header.Add(string(key), string(value))
+		})
+	}
+	return r.WithContext(c.UserContext())
+}
+
+// write writes a render.Response to the Fiber context.
+func write(c *fiber.Ctx, resp *render.Response) error {
+	for key, values := range resp.Header {
+		if key == "Vary" {
+			// Keep Vary values set by earlier handlers or middleware
+			c.Vary(values...)
+			continue
+		}
+		for i, value := range values {
+			if i == 0 {
+				c.Set(key, value)
+			} else {
+				c.Append(key, value)
+			}
+		}
+	}
+	return c.Status(resp.Status).Send(resp.Body)
+}
@@ -2,14 +2,20 @@ package fiberwrap_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/render"
 	"github.com/gosuda/httpwrap/wrapper/fiberwrap"
 )
 
@@ -169,3 +175,520 @@ func TestWrapper_HandleWithContentType(t *testing.T) {
 		}
 	}()
 }
+
+func TestWrapper_WithRenderer(t *testing.T) {
+	renderer := render.RendererFunc(func(request *http.Request, err error) *render.Response {
+		return render.Text(http.StatusTeapot, request.Method+" "+request.URL.Path)
+	})
+
+	w := fiberwrap.NewWrapper(fiberwrap.WithRenderer(renderer))
+	w.Get("/fail", func(c *fiber.Ctx) error {
+		return httperror.BadRequest("ignored by custom renderer")
+	})
+
+	resp, err := w.App().Test(httptest.NewRequest("GET", "/fail", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTeapot {
+		t.Fatalf("Expected status code %d, got %d", http.StatusTeapot, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+
+	if string(bytes.TrimSpace(data)) != "GET /fail" {
+		t.Fatalf("Unexpected response body: %s", string(data))
+	}
+}
+
+func TestWrapper_NegotiatesErrorFormat(t *testing.T) {
+	w := fiberwrap.NewWrapper()
+	w.Get("/users/:id", func(c *fiber.Ctx) error {
+		return httperror.NotFoundProblem9457("User not found")
+	})
+
+	tests := []struct {
+		accept              string
+		expectedContentType string
+	}{
+		{"", "application/problem+json"},
+		{"application/problem+xml", "application/problem+xml"},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", "text/html; charset=utf-8"},
+		{"text/plain", "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		resp, err := w.App().Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Accept %q: expected status code 404, got %d", tt.accept, resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != tt.expectedContentType {
+			t.Errorf("Accept %q: expected content type %s, got %s", tt.accept, tt.expectedContentType, ct)
+		}
+		if resp.Header.Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: expected Vary: Accept, got %q", tt.accept, resp.Header.Get("Vary"))
+		}
+	}
+}
+
+func TestWrapper_LocalizedErrors(t *testing.T) {
+	catalog := httperror.NewCatalog("en", "ko").
+		Set("ko", "404", "찾을 수 없음").
+		Set("ko", "user.not_found", "사용자 %s을(를) 찾을 수 없습니다")
+
+	w := fiberwrap.NewWrapper(fiberwrap.WithRenderer(render.New(render.WithCatalog(catalog))))
+	w.Get("/users/:id", func(c *fiber.Ctx) error {
+		return httperror.NotFound("User not found").WithMessageKey("user.not_found", c.Params("id"))
+	})
+
+	req := httptest.NewRequest("GET", "/users/kim", nil)
+	req.Header.Set("Accept-Language", "ko-KR,ko;q=0.9,en;q=0.8")
+	resp, err := w.App().Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if lang := resp.Header.Get("Content-Language"); lang != "ko" {
+		t.Fatalf("Expected Content-Language ko, got %q", lang)
+	}
+	if vary := resp.Header.Get("Vary"); vary != "Accept, Accept-Language" {
+		t.Fatalf("Expected Vary: Accept, Accept-Language, got %q", vary)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+
+	if string(data) != "사용자 kim을(를) 찾을 수 없습니다\n" {
+		t.Fatalf("Unexpected response body: %s", string(data))
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type order struct {
+		Customer string `json:"customer"`
+		Quantity int    `json:"quantity"`
+	}
+
+	w := fiberwrap.NewWrapper()
+	w.Post("/orders", func(c *fiber.Ctx) error {
+		var o order
+		if err := fiberwrap.DecodeJSON(c, &o); err != nil {
+			return err
+		}
+		return c.SendString(o.Customer)
+	})
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Valid", "application/json", `{"customer":"gopher","quantity":1}`, http.StatusOK, "gopher"},
+		{"Unsupported media type", "text/plain", `{}`, http.StatusUnsupportedMediaType, `"status":415`},
+		{"Type error", "application/json", `{"quantity":"one"}`, http.StatusBadRequest, `"pointer":"#/quantity"`},
+		{"Unknown field", "application/json", `{"coupon":"FREE"}`, http.StatusBadRequest, `unknown member \"coupon\"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/orders", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := w.App().Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response body: %v", err)
+			}
+			if !strings.Contains(string(data), tt.expectedBody) {
+				t.Fatalf("Expected body to contain %s, got %s", tt.expectedBody, data)
+			}
+		})
+	}
+}
+
+func TestTyped(t *testing.T) {
+	type updateUser struct {
+		ID     int    `path:"id" validate:"min=1"`
+		Notify bool   `query:"notify"`
+		Name   string `json:"name" validate:"required"`
+	}
+	type user struct {
+		ID     int    `json:"id"`
+		Name   string `json:"name"`
+		Notify bool   `json:"notify"`
+	}
+
+	w := fiberwrap.NewWrapper()
+	w.Put("/users/:id", fiberwrap.Typed(func(ctx context.Context, req updateUser) (user, error) {
+		return user{ID: req.ID, Name: req.Name, Notify: req.Notify}, nil
+	}))
+	w.Delete("/users/:id", fiberwrap.Typed(func(ctx context.Context, req struct {
+		ID int `path:"id" validate:"min=1"`
+	}) (*user, error) {
+		return nil, nil
+	}))
+
+	tests := []struct {
+		name                string
+		method              string
+		target              string
+		body                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{"Decodes body and parameters", "PUT", "/users/7?notify=true", `{"name":"gopher"}`, http.StatusOK, "application/json", `{"id":7,"name":"gopher","notify":true}`},
+		{"No content", "DELETE", "/users/7", ``, http.StatusNoContent, "", ``},
+		{"Invalid parameters and body", "PUT", "/users/0?notify=maybe", `{"name":""}`, http.StatusBadRequest, "application/problem+json",
+			`"errors":[{"parameter":"notify","in":"query","detail":"must be a boolean","code":"type"},{"parameter":"id","in":"path","detail":"must be at least 1","code":"min"},{"pointer":"#/name","detail":"is required","code":"required"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			resp, err := w.App().Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if resp.Header.Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("Expected content type %q, got %q", tt.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response body: %v", err)
+			}
+			if !strings.Contains(string(data), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, data)
+			}
+		})
+	}
+}
+
+func TestWrapper_RecoversPanics(t *testing.T) {
+	w := fiberwrap.NewWrapper()
+	w.Get("/panic", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	resp, err := w.App().Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status code 500, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Content-Type") != "application/problem+json" {
+		t.Errorf("Expected content type application/problem+json, got %s", resp.Header.Get("Content-Type"))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	if !strings.Contains(string(data), `"status":500`) || strings.Contains(string(data), "boom") {
+		t.Errorf("Expected a generic 500 problem, got %s", data)
+	}
+}
+
+func TestWrapper_EventCallback(t *testing.T) {
+	var (
+		route, method, problemType string
+		status                     int
+	)
+	w := fiberwrap.NewWrapper(fiberwrap.WithEventCallback(func(event *render.ErrorEvent) {
+		route = event.Route
+		method = event.Request.Method
+		status = event.Status
+		problemType = event.ProblemType
+	}))
+	w.Delete("/users/:id", func(c *fiber.Ctx) error {
+		return httperror.ConflictProblem9457("The user owns resources.")
+	})
+
+	req := httptest.NewRequest("DELETE", "/users/7", nil)
+	resp, err := w.App().Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	if route != "/users/:id" || method != "DELETE" {
+		t.Errorf("Route = %q, method = %q", route, method)
+	}
+	if status != http.StatusConflict {
+		t.Errorf("Status = %d, want 409", status)
+	}
+	if problemType == "" {
+		t.Error("Expected the problem type to be reported")
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	var events []*render.ErrorEvent
+	app := fiber.New(fiber.Config{
+		ErrorHandler: fiberwrap.ErrorHandler(fiberwrap.WithEventCallback(func(event *render.ErrorEvent) {
+			events = append(events, event)
+		})),
+	})
+	app.Use("/admin", func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return httperror.UnauthorizedProblem9457("Credentials are required.")
+		}
+		return c.Next()
+	})
+	app.Get("/admin/stats", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Post("/upload", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, "The file must not be larger than 1 MiB.")
+	})
+
+	w := fiberwrap.WithApp(app)
+	w.Get("/teapot", func(c *fiber.Ctx) error {
+		return fiber.ErrTeapot
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Middleware error", "GET", "/admin/stats", http.StatusUnauthorized, `"detail":"Credentials are required."`},
+		{"Fiber error with message", "POST", "/upload", http.StatusRequestEntityTooLarge, `"detail":"The file must not be larger than 1 MiB."`},
+		{"Fiber error from wrapped handler", "GET", "/teapot", http.StatusTeapot, `"title":"I'm a teapot"`},
+		{"Unknown route", "GET", "/missing", http.StatusNotFound, `"status":404`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.target, nil))
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if resp.Header.Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Expected content type application/problem+json, got %s", resp.Header.Get("Content-Type"))
+			}
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response body: %v", err)
+			}
+			if !strings.Contains(string(data), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, data)
+			}
+		})
+	}
+
+	// The wrapped handler reports its error itself, so it must not reach the ErrorHandler again
+	if len(events) != 3 {
+		t.Errorf("Expected 3 events from the ErrorHandler, got %d", len(events))
+	}
+}
+
+func TestNewWrapper_UnknownRoute(t *testing.T) {
+	w := fiberwrap.NewWrapper()
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	req.Header.Set("Accept", "application/problem+xml")
+	resp, err := w.App().Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status code 404, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Content-Type") != "application/problem+xml" {
+		t.Errorf("Expected content type application/problem+xml, got %s", resp.Header.Get("Content-Type"))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	if !strings.Contains(string(data), "<detail>Cannot GET /missing</detail>") {
+		t.Errorf("Expected Fiber's message as the detail, got %s", data)
+	}
+}
+
+func TestNewWrapper_UnknownRouteMatchesNotFoundProblem(t *testing.T) {
+	w := fiberwrap.NewWrapper()
+	w.Get("/users/:id", func(c *fiber.Ctx) error {
+		return httperror.NotFoundProblem9457("No such user.")
+	})
+
+	problem := func(target string) map[string]interface{} {
+		resp, err := w.App().Test(httptest.NewRequest("GET", target, nil))
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode problem: %v", err)
+		}
+		return body
+	}
+
+	unknown, handler := problem("/missing"), problem("/users/7")
+	for _, member := range []string{"type", "title", "status"} {
+		if unknown[member] != handler[member] {
+			t.Errorf("%s = %v for an unknown route, want %v as for NotFoundProblem9457", member, unknown[member], handler[member])
+		}
+	}
+	if unknown["type"] != httperror.CommonProblemTypes.ResourceNotFound {
+		t.Errorf("type = %v, want %s", unknown["type"], httperror.CommonProblemTypes.ResourceNotFound)
+	}
+	if unknown["detail"] != "Cannot GET /missing" {
+		t.Errorf("detail = %v, want Fiber's message", unknown["detail"])
+	}
+}
+
+func TestWrapper_FiberErrorCause(t *testing.T) {
+	w := fiberwrap.NewWrapper()
+	w.Get("/problem", func(c *fiber.Ctx) error {
+		return httperror.NotFoundProblem9457("x").WithCause(fiber.ErrBadGateway)
+	})
+	w.Get("/wrapped", func(c *fiber.Ctx) error {
+		return fmt.Errorf("upstream: %w", fiber.ErrBadGateway)
+	})
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/problem", http.StatusNotFound},
+		{"/wrapped", http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		resp, err := w.App().Test(httptest.NewRequest("GET", tt.path, nil))
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expectedStatus {
+			t.Errorf("%s: expected status code %d, got %d", tt.path, tt.expectedStatus, resp.StatusCode)
+		}
+	}
+}
+
+func TestWrapper_Groups(t *testing.T) {
+	var routes []string
+	w := fiberwrap.NewWrapper(fiberwrap.WithEventCallback(func(event *render.ErrorEvent) {
+		routes = append(routes, event.Route)
+	}))
+	w.Use(func(c *fiber.Ctx) error {
+		c.Set("X-Request-Id", "42")
+		return c.Next()
+	})
+
+	api := w.Group("/api/v1", func(c *fiber.Ctx) error {
+		if c.Get("Authorization") != "Bearer secret" {
+			return httperror.UnauthorizedProblem9457("A valid token is required.")
+		}
+		return c.Next()
+	})
+	api.Get("/users/:id", func(c *fiber.Ctx) error {
+		return c.SendString("user " + c.Params("id"))
+	})
+	api.Route("/orders", func(orders *fiberwrap.Wrapper) {
+		orders.Get("/:id", func(c *fiber.Ctx) error {
+			return httperror.NotFoundProblem9457("No such order.")
+		})
+	})
+
+	legacy := fiberwrap.NewWrapper()
+	legacy.Get("/status", func(c *fiber.Ctx) error {
+		return httperror.ServiceUnavailableProblem9457("The legacy API is retired.")
+	})
+	w.Mount("/legacy", legacy.App())
+
+	tests := []struct {
+		name           string
+		target         string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Group handler", "/api/v1/users/7", "Bearer secret", http.StatusOK, "user 7"},
+		{"Group middleware error", "/api/v1/users/7", "", http.StatusUnauthorized, `"detail":"A valid token is required."`},
+		{"Route handler error", "/api/v1/orders/9", "Bearer secret", http.StatusNotFound, `"detail":"No such order."`},
+		{"Mounted application", "/legacy/status", "", http.StatusServiceUnavailable, `"detail":"The legacy API is retired."`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := w.App().Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if resp.Header.Get("X-Request-Id") != "42" {
+				t.Error("Expected the root middleware to run")
+			}
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response body: %v", err)
+			}
+			if !strings.Contains(string(data), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, data)
+			}
+		})
+	}
+
+	expected := []string{"/api/v1", "/api/v1/orders/:id"}
+	if strings.Join(routes, " ") != strings.Join(expected, " ") {
+		t.Errorf("Reported routes %v, want %v", routes, expected)
+	}
+}
@@ -0,0 +1,55 @@
+package fiberwrap
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/gosuda/httpwrap/decode"
+	"github.com/gosuda/httpwrap/internal/typed"
+)
+
+// TypedHandlerFunc defines a handler that receives a decoded request value and returns a response value.
+// See Typed for how requests are decoded and responses are encoded.
+type TypedHandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// Typed converts a TypedHandlerFunc into a HandlerFunc that can be registered with Handle.
+//
+// The request is decoded with decode.Values: the JSON body, if any, is decoded into Req, then fields tagged
+// `path:"name"` are set from the route parameters and fields tagged `query:"name"` from the query string,
+// and finally structs are validated with validate.Struct. Malformed and invalid requests are rendered
+// as problems without calling handler. The handler receives the user context of the Fiber context.
+//
+// The response is encoded as JSON with status 200 OK, or the status returned by its StatusCode method if Resp
+// implements httperror.StatusCoder. Nil pointer responses and 204 No Content responses have no body.
+// Errors returned by handler are rendered like those of any other HandlerFunc.
+func Typed[Req, Resp any](handler TypedHandlerFunc[Req, Resp], opts ...decode.Option) HandlerFunc {
+	return func(c *fiber.Ctx) error {
+		// Fiber's values are only valid within the handler unless the app is Immutable,
+		// and decoded requests may outlive it
+		pathValue := func(name string) string {
+			return strings.Clone(c.Params(name))
+		}
+		query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+
+		var req Req
+		if err := decode.Values(c.Body(), c.Get(fiber.HeaderContentType), &req, pathValue, query, opts...); err != nil {
+			return err
+		}
+		resp, err := handler(c.UserContext(), req)
+		if err != nil {
+			return err
+		}
+
+		status, body, err := typed.Encode(resp)
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			c.Set(fiber.HeaderContentType, typed.ContentType)
+		}
+		return c.Status(status).Send(body)
+	}
+}
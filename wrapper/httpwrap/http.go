@@ -4,29 +4,76 @@
 package httpwrap
 
 import (
-	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/internal/response"
+	"github.com/gosuda/httpwrap/render"
 )
 
 // Mux wraps http.ServeMux with enhanced error handling capabilities.
 // It provides automatic error handling and supports custom error callbacks.
 type Mux struct {
-	mux           *http.ServeMux
-	errorCallback func(err error)
+	mux              *http.ServeMux
+	notFound         http.HandlerFunc
+	methodNotAllowed http.HandlerFunc
+	errorHandler     *response.ErrorHandler
+}
+
+// Option configures a Mux.
+type Option func(*Mux)
+
+// WithRenderer sets the Renderer used to convert handler errors into HTTP responses.
+// If renderer is nil, the default renderer is kept.
+func WithRenderer(renderer render.Renderer) Option {
+	return func(m *Mux) {
+		if renderer != nil {
+			m.errorHandler.Renderer = renderer
+		}
+	}
+}
+
+// WithEventCallback sets a callback that receives a *render.ErrorEvent for every error handled by the Mux,
+// describing the request, route pattern, response status, problem type and latency along with the error.
+// It is called after the error callback.
+func WithEventCallback(callback func(event *render.ErrorEvent)) Option {
+	return func(m *Mux) {
+		m.errorHandler.EventCallback = callback
+	}
+}
+
+// WithAbortAfterCommit makes the Mux abort the connection when a handler returns an error, or panics,
+// after it started writing the response, by panicking with http.ErrAbortHandler once the error is reported.
+// Without it the response is ended normally, and the client may mistake a truncated body for a complete one.
+func WithAbortAfterCommit() Option {
+	return func(m *Mux) {
+		m.errorHandler.AbortAfterCommit = true
+	}
 }
 
 // NewMux creates a new Mux with the specified error callback function.
 // If errorCallback is nil, a no-op function is used.
-func NewMux(errorCallback func(err error)) *Mux {
+func NewMux(errorCallback func(err error), opts ...Option) *Mux {
 	if errorCallback == nil {
 		errorCallback = func(err error) {}
 	}
-	return &Mux{
-		mux:           http.NewServeMux(),
-		errorCallback: errorCallback,
+	m := &Mux{
+		mux: http.NewServeMux(),
+		errorHandler: &response.ErrorHandler{
+			Renderer:      render.New(),
+			ErrorCallback: errorCallback,
+		},
+	}
+	m.errorHandler.Route = m.route
+	for _, opt := range opts {
+		opt(m)
 	}
+	m.NotFound(notFound)
+	m.MethodNotAllowed(methodNotAllowed)
+	return m
 }
 
 // HandlerFunc defines a function signature for HTTP handlers that can return errors.
@@ -35,29 +82,163 @@ type HandlerFunc func(http.ResponseWriter, *http.Request) error
 
 // Handle registers a new handler for the given pattern with automatic error handling.
 // If the handler returns an error, it will be automatically converted to an appropriate HTTP response.
+// If the handler panics, the panic is recovered and rendered as a 500 Internal Server Error problem,
+// and reported to the error callback as a *httperror.PanicError carrying the stack trace.
+// Panics with http.ErrAbortHandler are re-panicked so that net/http aborts the response.
+// Errors returned after the handler started writing the response are not rendered; see render.CommittedError.
 func (m *Mux) Handle(pattern string, handler HandlerFunc) {
-	m.mux.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
-		if err := handler(writer, request); err != nil {
-			he := &httperror.HttpError{}
-			switch errors.As(err, &he) {
-			case true:
-				// Set Content-Type if specified in HttpError
-				if he.ContentType != "" {
-					writer.Header().Set("Content-Type", he.ContentType)
-					writer.WriteHeader(he.Code)
-					writer.Write([]byte(he.Message))
-				} else {
-					http.Error(writer, he.Message, he.Code)
-				}
-			case false:
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
-			}
-			m.errorCallback(err)
-		}
-	})
+	m.mux.HandleFunc(pattern, m.wrap(handler))
+}
+
+// wrap converts a HandlerFunc into an http.HandlerFunc with the error handling described for Handle.
+func (m *Mux) wrap(handler HandlerFunc) http.HandlerFunc {
+	return m.errorHandler.Wrap(handler)
+}
+
+// NotFound sets the handler for requests that match no pattern, with automatic error handling.
+// By default, a 404 Not Found problem is rendered instead of the plain text response of http.ServeMux.
+func (m *Mux) NotFound(handler HandlerFunc) {
+	m.notFound = m.wrap(handler)
+}
+
+// MethodNotAllowed sets the handler for requests that match a pattern only with another method, with automatic
+// error handling. The Allow header computed by http.ServeMux is set before handler is called.
+// By default, a 405 Method Not Allowed problem is rendered instead of the plain text response of http.ServeMux.
+func (m *Mux) MethodNotAllowed(handler HandlerFunc) {
+	m.methodNotAllowed = m.wrap(handler)
+}
+
+// notFound is the default NotFound handler.
+func notFound(writer http.ResponseWriter, request *http.Request) error {
+	return httperror.NotFoundProblem9457("The requested resource was not found.")
+}
+
+// methodNotAllowed is the default MethodNotAllowed handler.
+func methodNotAllowed(writer http.ResponseWriter, request *http.Request) error {
+	return httperror.MethodNotAllowedProblem9457(fmt.Sprintf("The %s method is not allowed for the requested resource.", request.Method))
 }
 
 // ServeHTTP implements the http.Handler interface, delegating to the underlying ServeMux.
+// Requests that match no pattern are passed to the NotFound or MethodNotAllowed handler.
 func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	m.mux.ServeHTTP(w, r)
+	if muxGo121 {
+		// The ServeMux sets no pattern on requests to tell its built-in handlers apart,
+		// so the request is matched beforehand. It has no method patterns, hence no 405 responses.
+		if _, pattern := m.mux.Handler(r); pattern == "" {
+			m.notFound(w, r)
+			return
+		}
+		m.mux.ServeHTTP(w, r)
+		return
+	}
+
+	uw := &unmatchedWriter{ResponseWriter: w, request: r}
+	m.mux.ServeHTTP(uw, r)
+
+	switch uw.status {
+	case http.StatusNotFound:
+		m.notFound(w, r)
+	case http.StatusMethodNotAllowed:
+		w.Header().Set("Allow", uw.header.Get("Allow"))
+		m.methodNotAllowed(w, r)
+	}
+}
+
+// route returns the pattern that matched request, such as "GET /users/{id}".
+func (m *Mux) route(request *http.Request) string {
+	if muxGo121 {
+		// The ServeMux does not set the pattern on requests
+		_, pattern := m.mux.Handler(request)
+		return pattern
+	}
+	return request.Pattern
+}
+
+// muxGo121 reports whether http.ServeMux behaves as in Go 1.21, as it does with GODEBUG=httpmuxgo121=1.
+// The setting is read once when net/http is initialized, so it is detected by probing a ServeMux
+// with a wildcard pattern, which that ServeMux matches literally.
+var muxGo121 = func() bool {
+	probe := http.NewServeMux()
+	probe.HandleFunc("/{wildcard}", func(http.ResponseWriter, *http.Request) {})
+	_, pattern := probe.Handler(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/probe"}})
+	return pattern == ""
+}()
+
+// unmatchedWriter is the http.ResponseWriter passed to the ServeMux. It intercepts the 404 and 405 responses
+// of the ServeMux's built-in handlers, so that the request is routed only once, and passes any other response
+// through. The built-in handlers are told apart by the empty pattern the ServeMux sets on the request,
+// which it also sets for redirects to canonical paths. The ServeMux of Go 1.21 sets no pattern at all,
+// so unmatchedWriter is not used with GODEBUG=httpmuxgo121=1; see muxGo121.
+type unmatchedWriter struct {
+	http.ResponseWriter
+	request     *http.Request
+	header      http.Header // Headers set by a built-in handler, kept apart until its status is known
+	status      int         // Status of the intercepted response, or 0 if it was passed through
+	wroteHeader bool
+}
+
+// builtin reports whether the request is being served by one of the ServeMux's built-in handlers.
+func (u *unmatchedWriter) builtin() bool {
+	return u.request.Pattern == ""
+}
+
+// Header returns the headers of the response.
+func (u *unmatchedWriter) Header() http.Header {
+	if !u.wroteHeader && u.builtin() {
+		if u.header == nil {
+			u.header = make(http.Header)
+		}
+		return u.header
+	}
+	return u.ResponseWriter.Header()
+}
+
+// WriteHeader intercepts a 404 or 405 status sent by a built-in handler and passes any other status through.
+func (u *unmatchedWriter) WriteHeader(status int) {
+	if !u.wroteHeader {
+		u.wroteHeader = true
+		if u.builtin() {
+			if status == http.StatusNotFound || status == http.StatusMethodNotAllowed {
+				u.status = status
+				return
+			}
+			h := u.ResponseWriter.Header()
+			for key, values := range u.header {
+				h[key] = values
+			}
+		}
+	}
+	if u.status == 0 {
+		u.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write writes the body, or discards it if the response was intercepted.
+func (u *unmatchedWriter) Write(b []byte) (int, error) {
+	if !u.wroteHeader {
+		u.WriteHeader(http.StatusOK)
+	}
+	if u.status != 0 {
+		return len(b), nil
+	}
+	return u.ResponseWriter.Write(b)
+}
+
+// ReadFrom copies the body from r, delegating to the underlying ResponseWriter if it implements io.ReaderFrom.
+func (u *unmatchedWriter) ReadFrom(r io.Reader) (int64, error) {
+	if !u.wroteHeader {
+		u.WriteHeader(http.StatusOK)
+	}
+	if u.status != 0 {
+		return io.Copy(io.Discard, r)
+	}
+	if rf, ok := u.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(u.ResponseWriter, r)
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by http.ResponseController.
+func (u *unmatchedWriter) Unwrap() http.ResponseWriter {
+	return u.ResponseWriter
 }
@@ -1,11 +1,17 @@
 package httpwrap
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
 
 	"github.com/gosuda/httpwrap/httperror"
+	"github.com/gosuda/httpwrap/render"
 )
 
 func TestMux_HandleWithContentType(t *testing.T) {
@@ -52,6 +58,14 @@ func TestMux_HandleWithContentType(t *testing.T) {
 			expectedStatus:      400,
 			expectedContentType: "application/problem+json",
 		},
+		{
+			name: "RFC9457 error returned directly",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return httperror.NotFoundProblem9457("User not found")
+			},
+			expectedStatus:      404,
+			expectedContentType: "application/problem+json",
+		},
 	}
 
 	for _, tt := range tests {
@@ -120,3 +134,617 @@ func TestMux_HandleWithoutError(t *testing.T) {
 		t.Errorf("Expected body OK, got %s", w.Body.String())
 	}
 }
+
+func TestMux_WithRenderer(t *testing.T) {
+	renderer := render.RendererFunc(func(request *http.Request, err error) *render.Response {
+		return &render.Response{
+			Status: http.StatusTeapot,
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   []byte(`{"custom":true}`),
+		}
+	})
+
+	var reported error
+	mux := NewMux(func(err error) { reported = err }, WithRenderer(renderer))
+	mux.Handle("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.BadRequest("ignored by custom renderer")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected status %d, got %d", http.StatusTeapot, w.Code)
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected content type application/json, got %s", w.Header().Get("Content-Type"))
+	}
+	if w.Body.String() != `{"custom":true}` {
+		t.Errorf("Expected custom body, got %s", w.Body.String())
+	}
+	if reported == nil {
+		t.Error("Expected error callback to be called")
+	}
+}
+
+func TestMux_ErrorCause(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+
+	var reported error
+	mux := NewMux(func(err error) { reported = err })
+	mux.Handle("/users/1", func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NotFoundProblem9457("User not found").WithCause(cause)
+	})
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "sql") {
+		t.Errorf("Expected cause to be hidden from the response, got %s", w.Body.String())
+	}
+	if !errors.Is(reported, cause) {
+		t.Errorf("Expected callback error to wrap the cause, got %v", reported)
+	}
+}
+
+func TestMux_UnknownErrorIsNotLeaked(t *testing.T) {
+	internal := errors.New("dial tcp 10.0.0.12:5432: connection refused")
+
+	var reported error
+	mux := NewMux(func(err error) { reported = err })
+	mux.Handle("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return internal
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "10.0.0.12") {
+		t.Errorf("Expected internal error to be hidden, got %s", w.Body.String())
+	}
+
+	var ref *render.ReferenceError
+	if !errors.As(reported, &ref) {
+		t.Fatalf("Expected callback to receive a ReferenceError, got %v", reported)
+	}
+	if !errors.Is(reported, internal) {
+		t.Errorf("Expected callback error to wrap the original error")
+	}
+	if !strings.Contains(w.Body.String(), ref.Reference) {
+		t.Errorf("Expected response to contain reference %s, got %s", ref.Reference, w.Body.String())
+	}
+}
+
+func TestMux_UnknownErrorInDevelopmentMode(t *testing.T) {
+	mux := NewMux(nil, WithRenderer(render.New(render.WithDevelopment())))
+	mux.Handle("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("verbose details")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if w.Body.String() != "verbose details\n" {
+		t.Errorf("Expected verbose body, got %s", w.Body.String())
+	}
+}
+
+func TestMux_ProblemTypeDocs(t *testing.T) {
+	registry := httperror.NewRegistry()
+	registry.MustRegister(httperror.ProblemType{
+		URI:    "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: http.StatusForbidden,
+	})
+
+	mux := NewMux(nil)
+	mux.Handle("/probs/", httperror.NewDocsHandler(registry, "https://example.com/probs").Serve)
+
+	req := httptest.NewRequest("GET", "/probs/out-of-credit", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "You do not have enough credit.") {
+		t.Errorf("Expected documentation page, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/probs/unknown", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("Expected problem content type, got %s", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestMux_NegotiatesErrorFormat(t *testing.T) {
+	mux := NewMux(nil)
+	mux.Handle("/test", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Vary", "Origin")
+		return httperror.BadRequestProblem9457("Invalid input data").ToHttpError()
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "application/problem+xml" {
+		t.Errorf("Expected content type application/problem+xml, got %s", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "<detail>Invalid input data</detail>") {
+		t.Errorf("Expected XML problem body, got %s", w.Body.String())
+	}
+	if vary := w.Header().Values("Vary"); len(vary) != 2 || vary[0] != "Origin" || vary[1] != "Accept" {
+		t.Errorf("Expected Vary to be Origin and Accept, got %q", vary)
+	}
+}
+
+func TestMux_HTMLErrorPage(t *testing.T) {
+	mux := NewMux(nil)
+	mux.Handle("/users/1", func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NotFoundProblem7807("User <1> not found")
+	})
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("Expected content type text/html; charset=utf-8, got %s", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "<p>User &lt;1&gt; not found</p>") {
+		t.Errorf("Expected escaped HTML page, got %s", w.Body.String())
+	}
+}
+
+func TestMux_ValidationErrors(t *testing.T) {
+	mux := NewMux(nil)
+	mux.Handle("/users", func(w http.ResponseWriter, r *http.Request) error {
+		errs := httperror.NewValidationErrors()
+		if r.URL.Query().Get("name") == "" {
+			errs.AddParameter("name", "is required", "required")
+		}
+		return errs.Err()
+	})
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("Expected content type application/problem+json, got %s", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), `"errors":[{"parameter":"name","in":"query","detail":"is required","code":"required"}]`) {
+		t.Errorf("Expected errors extension, got %s", w.Body.String())
+	}
+}
+
+type updateUser struct {
+	ID     int    `path:"id" validate:"min=1"`
+	Notify bool   `query:"notify"`
+	Name   string `json:"name" validate:"required"`
+}
+
+type createUser struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type userID struct {
+	ID int `path:"id" validate:"min=1"`
+}
+
+type user struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Notify bool   `json:"notify"`
+}
+
+type created struct {
+	user
+}
+
+func (created) StatusCode() int { return http.StatusCreated }
+
+func TestMux_Typed(t *testing.T) {
+	mux := NewMux(nil)
+	mux.Handle("PUT /users/{id}", Typed(func(ctx context.Context, req updateUser) (user, error) {
+		return user{ID: req.ID, Name: req.Name, Notify: req.Notify}, nil
+	}))
+	mux.Handle("POST /users", Typed(func(ctx context.Context, req createUser) (created, error) {
+		return created{user{ID: 1, Name: req.Name}}, nil
+	}))
+	mux.Handle("DELETE /users/{id}", Typed(func(ctx context.Context, req userID) (*user, error) {
+		if req.ID == 2 {
+			return nil, httperror.NotFoundProblem9457("No such user.")
+		}
+		return nil, nil
+	}))
+
+	tests := []struct {
+		name                string
+		method              string
+		target              string
+		body                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{"Decodes body and parameters", "PUT", "/users/7?notify=true", `{"name":"gopher"}`, http.StatusOK, "application/json", `{"id":7,"name":"gopher","notify":true}`},
+		{"Custom status", "POST", "/users", `{"name":"gopher"}`, http.StatusCreated, "application/json", `{"id":1,"name":"gopher","notify":false}`},
+		{"No content", "DELETE", "/users/1", ``, http.StatusNoContent, "", ``},
+		{"Handler error", "DELETE", "/users/2", ``, http.StatusNotFound, "application/problem+json", `"detail":"No such user."`},
+		{"Malformed body", "PUT", "/users/7", `{"name":`, http.StatusBadRequest, "application/problem+json", `incomplete JSON`},
+		{"Invalid parameters and body", "PUT", "/users/0?notify=maybe", `{"name":""}`, http.StatusBadRequest, "application/problem+json",
+			`"errors":[{"parameter":"notify","in":"query","detail":"must be a boolean","code":"type"},{"parameter":"id","in":"path","detail":"must be at least 1","code":"min"},{"pointer":"#/name","detail":"is required","code":"required"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("Expected content type %q, got %q", tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMux_RecoversPanics(t *testing.T) {
+	var reported error
+	mux := NewMux(func(err error) { reported = err })
+	mux.Handle("/panic", func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("Expected content type application/problem+json, got %s", w.Header().Get("Content-Type"))
+	}
+	if strings.Contains(w.Body.String(), "boom") {
+		t.Errorf("Panic value leaked to the client: %s", w.Body.String())
+	}
+
+	var pe *httperror.PanicError
+	if !errors.As(reported, &pe) {
+		t.Fatalf("Expected a *httperror.PanicError to be reported, got %v", reported)
+	}
+	if pe.Value != "boom" || len(pe.Stack) == 0 {
+		t.Errorf("Reported %v with a stack of %d bytes", pe.Value, len(pe.Stack))
+	}
+}
+
+func TestMux_RecoversPanicsWithStatusCoder(t *testing.T) {
+	mux := NewMux(func(err error) {})
+	mux.Handle("/panic", func(w http.ResponseWriter, r *http.Request) error {
+		panic(httperror.Forbidden("secret internal detail"))
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "secret internal detail") {
+		t.Errorf("Panic value leaked to the client: %s", w.Body.String())
+	}
+}
+
+func TestMux_RepanicsAbortHandler(t *testing.T) {
+	called := false
+	mux := NewMux(func(err error) { called = true })
+	mux.Handle("/abort", func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler to be re-panicked, got %v", v)
+		}
+		if called {
+			t.Error("Aborts should not be reported to the error callback")
+		}
+	}()
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+}
+
+func TestMux_ErrorAfterCommit(t *testing.T) {
+	var reported error
+	mux := NewMux(func(err error) { reported = err })
+	mux.Handle("/stream", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("id,name\n1,"))
+		return errors.New("database connection lost")
+	})
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "id,name\n1," {
+		t.Errorf("Expected the partial response to be left untouched, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("Expected content type text/csv, got %s", w.Header().Get("Content-Type"))
+	}
+
+	var committed *render.CommittedError
+	if !errors.As(reported, &committed) {
+		t.Fatalf("Expected a *render.CommittedError to be reported, got %v", reported)
+	}
+	if committed.Err.Error() != "database connection lost" {
+		t.Errorf("Expected the handler error to be wrapped, got %v", committed.Err)
+	}
+}
+
+func TestMux_AbortAfterCommit(t *testing.T) {
+	var reported error
+	mux := NewMux(func(err error) { reported = err }, WithAbortAfterCommit())
+	mux.Handle("/stream", func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler, got %v", v)
+		}
+		var pe *httperror.PanicError
+		if !errors.As(reported, &pe) {
+			t.Errorf("Expected the panic to be reported before aborting, got %v", reported)
+		}
+	}()
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/stream", nil))
+}
+
+func TestMux_Flusher(t *testing.T) {
+	mux := NewMux(nil)
+	mux.Handle("/events", func(w http.ResponseWriter, r *http.Request) error {
+		if _, ok := w.(http.Flusher); !ok {
+			return errors.New("http.Flusher not implemented")
+		}
+		return http.NewResponseController(w).Flush()
+	})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+
+	if !w.Flushed {
+		t.Error("Expected the response to be flushed")
+	}
+}
+
+func TestMux_EventCallback(t *testing.T) {
+	var events []*render.ErrorEvent
+	mux := NewMux(nil, WithEventCallback(func(event *render.ErrorEvent) {
+		events = append(events, event)
+	}))
+	mux.Handle("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NotFoundProblem9457("No such user.").WithType("https://example.com/probs/no-user")
+	})
+	mux.Handle("GET /export", func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return errors.New("export failed")
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7", nil))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/export", nil))
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.Route != "GET /users/{id}" || event.Request.URL.Path != "/users/7" {
+		t.Errorf("Route = %q, path = %q", event.Route, event.Request.URL.Path)
+	}
+	if event.Status != http.StatusNotFound || !event.IsClientError() {
+		t.Errorf("Status = %d, want a 404 client error", event.Status)
+	}
+	if event.ProblemType != "https://example.com/probs/no-user" {
+		t.Errorf("ProblemType = %q", event.ProblemType)
+	}
+	if event.Latency <= 0 {
+		t.Errorf("Latency = %v, want a positive duration", event.Latency)
+	}
+
+	event = events[1]
+	if !event.Committed || event.Status != http.StatusAccepted {
+		t.Errorf("Committed = %v, Status = %d, want a committed 202", event.Committed, event.Status)
+	}
+	var committed *render.CommittedError
+	if !errors.As(event.Err, &committed) {
+		t.Errorf("Err = %v, want a *render.CommittedError", event.Err)
+	}
+}
+
+func TestMux_NotFoundAndMethodNotAllowed(t *testing.T) {
+	var reported []error
+	mux := NewMux(func(err error) { reported = append(reported, err) })
+	noop := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	mux.Handle("GET /users/{id}", noop)
+	mux.Handle("DELETE /users/{id}", noop)
+	mux.Handle("/files/", noop)
+	mux.Handle("GET /gone", func(w http.ResponseWriter, r *http.Request) error {
+		http.NotFound(w, r)
+		return nil
+	})
+
+	tests := []struct {
+		name                string
+		method              string
+		target              string
+		expectedStatus      int
+		expectedContentType string
+		expectedAllow       string
+		expectedLocation    string
+	}{
+		{"Unknown route", "GET", "/missing", http.StatusNotFound, "application/problem+json", "", ""},
+		{"Wrong method", "POST", "/users/7", http.StatusMethodNotAllowed, "application/problem+json", "DELETE, GET, HEAD", ""},
+		{"Redirect", "GET", "/files", http.StatusTemporaryRedirect, "text/html; charset=utf-8", "", "/files/"},
+		{"Handler response", "GET", "/gone", http.StatusNotFound, "text/plain; charset=utf-8", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("Expected content type %q, got %q", tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			if w.Header().Get("Allow") != tt.expectedAllow {
+				t.Errorf("Allow = %q, want %q", w.Header().Get("Allow"), tt.expectedAllow)
+			}
+			if w.Header().Get("Location") != tt.expectedLocation {
+				t.Errorf("Location = %q, want %q", w.Header().Get("Location"), tt.expectedLocation)
+			}
+		})
+	}
+
+	if len(reported) != 2 {
+		t.Errorf("Expected the 404 and 405 to be reported, got %v", reported)
+	}
+}
+
+func TestMux_CustomNotFound(t *testing.T) {
+	mux := NewMux(nil)
+	mux.Handle("GET /users", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	mux.NotFound(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NotFoundProblem9457("No route matches " + r.URL.Path + ".")
+	})
+	mux.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.MethodNotAllowedProblem9457("Allowed methods: " + w.Header().Get("Allow") + ".")
+	})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "No route matches /missing.") {
+		t.Errorf("Expected the custom 404 problem, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/users", nil))
+	if w.Code != http.StatusMethodNotAllowed || !strings.Contains(w.Body.String(), "Allowed methods: GET, HEAD.") {
+		t.Errorf("Expected the custom 405 problem, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMux_HTTPMuxGo121(t *testing.T) {
+	if !muxGo121 {
+		// The setting is read once at startup, so the test runs itself again with it
+		cmd := exec.Command(os.Args[0], "-test.run=^TestMux_HTTPMuxGo121$", "-test.v")
+		cmd.Env = append(os.Environ(), "GODEBUG=httpmuxgo121=1")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Test with GODEBUG=httpmuxgo121=1 failed: %v\n%s", err, out)
+		}
+		if !strings.Contains(string(out), "--- PASS: TestMux_HTTPMuxGo121") {
+			t.Fatalf("Test with GODEBUG=httpmuxgo121=1 did not run:\n%s", out)
+		}
+		return
+	}
+
+	var route string
+	mux := NewMux(nil, WithEventCallback(func(event *render.ErrorEvent) {
+		route = event.Route
+	}))
+	mux.Handle("/users/", func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/users/missing" {
+			return httperror.NotFoundProblem9457("No such user.")
+		}
+		http.NotFound(w, r)
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		contains string
+	}{
+		{"Unknown path", "/missing", http.StatusNotFound, `"detail":"The requested resource was not found."`},
+		{"Handler problem", "/users/missing", http.StatusNotFound, `"detail":"No such user."`},
+		{"Handler response", "/users/other", http.StatusNotFound, "404 page not found"},
+		{"Redirect", "/users", http.StatusMovedPermanently, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("Expected body to contain %s, got %s", tt.contains, w.Body.String())
+			}
+		})
+	}
+	if route != "/users/" {
+		t.Errorf("Route = %q, want %q", route, "/users/")
+	}
+}